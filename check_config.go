@@ -0,0 +1,336 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	stdlog "log"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"go-infoblox-exporter/probes"
+	"go-infoblox-exporter/secrets"
+)
+
+type configKind int
+
+const (
+	kindString configKind = iota
+	kindInt
+	kindBool
+	kindStringList
+	kindIntList
+	kindStringOrList
+)
+
+// configSchema is all keys that can be set in the configuration file
+var configSchema = map[string]configKind{
+	"exporter.port":                             kindInt,
+	"exporter.logfile":                          kindString,
+	"exporter.logformat":                        kindString,
+	"exporter.config":                           kindString,
+	"exporter.ready.cache_seconds":              kindInt,
+	"exporter.ready.timeout_seconds":            kindInt,
+	"exporter.shutdown_grace_period":            kindInt,
+	"exporter.basic_auth.username":              kindString,
+	"exporter.basic_auth.password":              kindString,
+	"exporter.basic_auth.password_file":         kindString,
+	"exporter.tls.cert_file":                    kindString,
+	"exporter.tls.key_file":                     kindString,
+	"exporter.tls.client_ca_file":               kindString,
+	"exporter.tls.client_auth_type":             kindString,
+	"exporter.tls.min_version":                  kindString,
+	"exporter.tls.cipher_suites":                kindStringList,
+	"infoblox.master":                           kindStringOrList,
+	"infoblox.master_port":                      kindInt,
+	"infoblox.wapi_version":                     kindString,
+	"infoblox.username":                         kindString,
+	"infoblox.password":                         kindString,
+	"infoblox.password_file":                    kindString,
+	"infoblox.ssl_verify":                       kindBool,
+	"infoblox.http_request_timeout":             kindInt,
+	"infoblox.http_pool_connections":            kindInt,
+	"infoblox.page_size":                        kindInt,
+	"infoblox.max_concurrent_requests":          kindInt,
+	"infoblox.max_queued_requests":              kindInt,
+	"infoblox.requests_per_second":              kindInt,
+	"infoblox.circuit_breaker.max_failures":     kindInt,
+	"infoblox.circuit_breaker.open_seconds":     kindInt,
+	"infoblox.client_cert_file":                 kindString,
+	"infoblox.client_key_file":                  kindString,
+	"infoblox.ca_file":                          kindString,
+	"modules.stale_records.days":                kindIntList,
+	"modules.threat_protection.rule_type_zones": kindStringList,
+}
+
+// secretKeys can have a secret provider configured as <key>_provider
+var secretKeys = []string{
+	"exporter.basic_auth.password",
+	"infoblox.password",
+}
+
+// requiredKeys must have a value in the configuration file or as env var
+var requiredKeys = []string{
+	"infoblox.master",
+	"infoblox.wapi_version",
+}
+
+func init() {
+	for _, key := range secretKeys {
+		configSchema[key+"_provider.type"] = kindString
+		configSchema[key+"_provider.path"] = kindString
+		configSchema[key+"_provider.name"] = kindString
+		configSchema[key+"_provider.url"] = kindString
+		configSchema[key+"_provider.field"] = kindString
+		configSchema[key+"_provider.refresh"] = kindInt
+	}
+}
+
+// readConfigurationFile read the configuration file into a new viper instance, without defaults
+// and env vars, so only the content of the file is validated
+func readConfigurationFile(configFile string) (*viper.Viper, error) {
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfiguration(content)
+}
+
+// parseConfiguration read the content of a configuration file into a new viper instance, without
+// defaults and env vars
+func parseConfiguration(content []byte) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// configurationProblems return unknown keys, keys with the wrong type and missing required keys
+func configurationProblems(v *viper.Viper) []string {
+	var problems []string
+
+	keys := v.AllKeys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		kind, ok := configSchema[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown key %s", key))
+			continue
+		}
+		err := checkKind(v.Get(key), kind)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s %v", key, err))
+		}
+	}
+
+	for _, key := range requiredKeys {
+		if !isConfigured(v, key) {
+			problems = append(problems, fmt.Sprintf("required key %s is not set", key))
+		}
+	}
+
+	if !isConfigured(v, "infoblox.username") && !isConfigured(v, "infoblox.client_cert_file") {
+		problems = append(problems, "infoblox.username or infoblox.client_cert_file must be set")
+	}
+
+	for _, key := range secretKeys {
+		err := secrets.Validate(v, key)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+func isConfigured(v *viper.Viper, key string) bool {
+	return len(v.GetStringSlice(key)) > 0 || os.Getenv(keyAsEnv(key)) != ""
+}
+
+// keyAsEnv return the env var name for the key, like INFOBLOX_EXPORTER_INFOBLOX_MASTER
+func keyAsEnv(key string) string {
+	return ExporterNameAsEnv() + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func checkKind(value interface{}, kind configKind) error {
+	if value == nil {
+		return nil
+	}
+
+	switch kind {
+	case kindString:
+		switch value.(type) {
+		case string, int, int64:
+			return nil
+		case float64:
+			// A version like 2.10 is parsed as the float 2.1
+			return fmt.Errorf("must be a string, quote the value")
+		}
+		return fmt.Errorf("must be a string")
+	case kindStringOrList:
+		if _, ok := value.([]interface{}); ok {
+			return checkKind(value, kindStringList)
+		}
+		return checkKind(value, kindString)
+	case kindInt:
+		return checkInt(value)
+	case kindBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("must be true or false")
+		}
+		return nil
+	case kindStringList, kindIntList:
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("must be a list")
+		}
+		for _, item := range list {
+			var err error
+			if kind == kindIntList {
+				err = checkInt(item)
+			} else {
+				err = checkKind(item, kindString)
+			}
+			if err != nil {
+				return fmt.Errorf("item %v %v", item, err)
+			}
+		}
+	}
+	return nil
+}
+
+func checkInt(value interface{}) error {
+	switch v := value.(type) {
+	case int, int64:
+		return nil
+	case string:
+		if _, err := strconv.Atoi(v); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be an integer")
+}
+
+// checkConfiguration validate the configuration file and the connection to the grid, print a report
+// and return the exit code. The readErr is the error from reading the configuration file at start.
+func checkConfiguration(readErr error) int {
+	// Only the report is written, the ibclient use the standard logger
+	log.SetLevel(log.FatalLevel)
+	stdlog.SetOutput(io.Discard)
+
+	failed := false
+	report := func(ok bool, format string, a ...interface{}) {
+		status := "OK  "
+		if !ok {
+			status = "FAIL"
+			failed = true
+		}
+		// WAPI errors include the response body on separate lines
+		message := strings.Join(strings.Fields(fmt.Sprintf(format, a...)), " ")
+		fmt.Printf("  %s %s\n", status, message)
+	}
+
+	if readErr != nil {
+		fmt.Println("Checking configuration")
+		report(false, "configuration file not valid: %v", readErr)
+		return 1
+	}
+
+	fmt.Printf("Checking configuration %s\n", viper.ConfigFileUsed())
+	v, err := readConfigurationFile(viper.ConfigFileUsed())
+	if err != nil {
+		report(false, "configuration file not valid: %v", err)
+		return 1
+	}
+	report(true, "configuration file parsed")
+
+	problems := configurationProblems(v)
+	for _, problem := range problems {
+		report(false, "%s", problem)
+	}
+	if len(problems) == 0 {
+		report(true, "configuration keys and types valid")
+	}
+
+	masters := viper.GetStringSlice("infoblox.master")
+	if len(masters) == 0 {
+		return 1
+	}
+	fmt.Printf("Checking grid %s\n", masters[0])
+	for _, master := range masters {
+		addresses, err := net.LookupHost(master)
+		if err != nil {
+			report(false, "resolve %s: %v", master, err)
+			continue
+		}
+		report(true, "resolved %s to %s", master, strings.Join(addresses, ", "))
+	}
+	caFile := viper.GetString("infoblox.ca_file")
+	if caFile != "" {
+		if !viper.GetBool("infoblox.ssl_verify") {
+			// The grid master certificate is not verified, also not against the CA file
+			report(false, "infoblox.ca_file %s is set but not used since infoblox.ssl_verify is false", caFile)
+		} else if err := probes.CheckCAFile(caFile); err != nil {
+			report(false, "read CA file: %v", err)
+		} else {
+			report(true, "read CA file %s", caFile)
+		}
+	}
+	if failed {
+		return 1
+	}
+
+	api, err := probes.NewInfobloxApi(viper.GetViper())
+	if err != nil {
+		report(false, "create connection: %v", err)
+		return 1
+	}
+
+	// Every address is checked, also the addresses only used when the grid master fail over
+	for _, masterApi := range api.MasterApis() {
+		checkMaster(masterApi, report)
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// checkMaster log in to the address of the api and check that the WAPI version is supported
+func checkMaster(api probes.InfoBloxApi, report func(ok bool, format string, a ...interface{})) {
+	defer api.Logout()
+
+	schema, err := api.GetSchema()
+	if err != nil {
+		report(false, "login to %s with WAPI version %s: %v", api.ActiveMaster(), api.Config.Version, err)
+		return
+	}
+	report(true, "logged in to %s with WAPI version %s", api.ActiveMaster(), api.Config.Version)
+
+	supported := slices.Contains(schema.SupportedVersions, api.Config.Version)
+	report(supported, "WAPI version %s supported by %s, supported versions %s", api.Config.Version,
+		api.ActiveMaster(), strings.Join(schema.SupportedVersions, ", "))
+}
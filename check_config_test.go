@@ -0,0 +1,117 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCheckKind(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		kind  configKind
+		err   string
+	}{
+		{"nil", nil, kindInt, ""},
+		{"string", "foo", kindString, ""},
+		{"int as string", 443, kindString, ""},
+		{"float as string", 2.1, kindString, "must be a string, quote the value"},
+		{"bool as string", true, kindString, "must be a string"},
+		{"string or list with string", "foo", kindStringOrList, ""},
+		{"string or list with list", []interface{}{"foo", "bar"}, kindStringOrList, ""},
+		{"string or list with bool item", []interface{}{"foo", true}, kindStringOrList, "item true must be a string"},
+		{"int", 10, kindInt, ""},
+		{"int from env", "10", kindInt, ""},
+		{"int as text", "ten", kindInt, "must be an integer"},
+		{"float as int", 1.5, kindInt, "must be an integer"},
+		{"bool", false, kindBool, ""},
+		{"string as bool", "true", kindBool, "must be true or false"},
+		{"string list", []interface{}{"a", "b"}, kindStringList, ""},
+		{"string as string list", "a", kindStringList, "must be a list"},
+		{"int list", []interface{}{7, 30}, kindIntList, ""},
+		{"int list with text", []interface{}{7, "month"}, kindIntList, "item month must be an integer"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkKind(test.value, test.kind)
+			if test.err == "" {
+				if err != nil {
+					t.Errorf("got error %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.err {
+				t.Errorf("got error %v, want %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestConfigurationProblems(t *testing.T) {
+	const valid = `
+infoblox:
+  master: infoblox.example.com
+  wapi_version: "2.10.5"
+  username: foo
+`
+	tests := []struct {
+		name     string
+		config   string
+		problems []string
+	}{
+		{"valid", valid, nil},
+		{"master list", `
+infoblox:
+  master:
+    - gm.example.com
+    - gmc.example.com
+  wapi_version: "2.10.5"
+  client_cert_file: /etc/infoblox-exporter/client.crt
+`, nil},
+		{"unknown key", valid + "  pasword: bar\n", []string{"unknown key infoblox.pasword"}},
+		{"unknown section", valid + "modules:\n  stale:\n    days: 7\n", []string{"unknown key modules.stale.days"}},
+		{"wrong type", valid + "  page_size: all\n", []string{"infoblox.page_size must be an integer"}},
+		{"unquoted version", `
+infoblox:
+  master: infoblox.example.com
+  wapi_version: 2.10
+  username: foo
+`, []string{"infoblox.wapi_version must be a string, quote the value"}},
+		{"nested list", valid + "modules:\n  stale_records:\n    days: [7, month]\n",
+			[]string{"modules.stale_records.days item month must be an integer"}},
+		{"missing required keys", "infoblox:\n  username: foo\n",
+			[]string{"required key infoblox.master is not set", "required key infoblox.wapi_version is not set"}},
+		{"missing credentials", "infoblox:\n  master: infoblox.example.com\n  wapi_version: \"2.10.5\"\n",
+			[]string{"infoblox.username or infoblox.client_cert_file must be set"}},
+		{"secret provider", valid + "  password_provider:\n    type: env\n    name: INFOBLOX_PASSWORD\n", nil},
+		{"incomplete secret provider", valid + "  password_provider:\n    type: http\n    url: http://localhost\n",
+			[]string{"infoblox.password_provider.field must be set for the http secret provider"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := parseConfiguration([]byte(test.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			problems := configurationProblems(v)
+			if !slices.Equal(problems, test.problems) {
+				t.Errorf("got %q, want %q", problems, test.problems)
+			}
+		})
+	}
+}
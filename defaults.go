@@ -32,48 +32,86 @@ func ExporterNameAsEnv() string {
 	return strings.ToUpper(strings.ReplaceAll(ExporterName, "-", "_"))
 }
 
-// SetDefaultValues define all default values
-func SetDefaultValues() {
+// SetDefaultValues define all default values and env vars of the configuration
+func SetDefaultValues(v *viper.Viper) {
 
 	// If set as env vars use the ExporterName as prefix like ACI_STREAMER_PORT for the port var
-	viper.SetEnvPrefix(ExporterNameAsEnv())
+	v.SetEnvPrefix(ExporterNameAsEnv())
 
 	// All fields with . will be replaced with _ for ENV vars
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	viper.AutomaticEnv()
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
 
 	// infoblox-exporter
-	viper.SetDefault("exporter.port", 9597)
-	viper.BindEnv("exporter.port")
-	viper.SetDefault("exporter.logfile", "")
-	viper.BindEnv("exporter.logfile")
-	viper.SetDefault("exporter.logformat", "json")
-	viper.BindEnv("exporter.logformat")
-	viper.SetDefault("exporter.config", "config")
-	viper.BindEnv("exporter.config")
+	v.SetDefault("exporter.port", 9597)
+	v.BindEnv("exporter.port")
+	v.SetDefault("exporter.logfile", "")
+	v.BindEnv("exporter.logfile")
+	v.SetDefault("exporter.logformat", "json")
+	v.BindEnv("exporter.logformat")
+	v.SetDefault("exporter.config", "config")
+	v.BindEnv("exporter.config")
+	v.SetDefault("exporter.ready.cache_seconds", 30)
+	v.BindEnv("exporter.ready.cache_seconds")
+	v.SetDefault("exporter.ready.timeout_seconds", 10)
+	v.BindEnv("exporter.ready.timeout_seconds")
+	v.SetDefault("exporter.shutdown_grace_period", 30)
+	v.BindEnv("exporter.shutdown_grace_period")
 
 	// Basic auth exporter
-	//viper.SetDefault("exporter.basic_auth.username)
-	viper.BindEnv("exporter.basic_auth.username")
-	//viper.SetDefault("exporter.basic_auth.password", "")
-	viper.BindEnv("exporter.basic_auth.password")
+	//v.SetDefault("exporter.basic_auth.username)
+	v.BindEnv("exporter.basic_auth.username")
+	//v.SetDefault("exporter.basic_auth.password", "")
+	v.BindEnv("exporter.basic_auth.password")
+	v.BindEnv("exporter.basic_auth.password_file")
+
+	// TLS exporter
+	v.BindEnv("exporter.tls.cert_file")
+	v.BindEnv("exporter.tls.key_file")
+	v.BindEnv("exporter.tls.client_ca_file")
+	v.BindEnv("exporter.tls.client_auth_type")
+	v.BindEnv("exporter.tls.min_version")
+	v.BindEnv("exporter.tls.cipher_suites")
 
 	// Infoblox master
-	viper.SetDefault("infoblox.master", "")
-	viper.BindEnv("infoblox.master")
-	viper.SetDefault("infoblox.master_port", "")
-	viper.BindEnv("infoblox.master_port")
-	viper.SetDefault("infoblox.wapi_version", "")
-	viper.BindEnv("infoblox.wapi_version")
-	viper.SetDefault("infoblox.username", "")
-	viper.BindEnv("infoblox.username")
-	viper.SetDefault("infoblox.password", "")
-	viper.BindEnv("infoblox.password")
-	viper.SetDefault("infoblox.ssl_verify", false)
-	viper.BindEnv("infoblox.ssl_verify")
-	viper.SetDefault("infoblox.http_request_timeout", 20)
-	viper.BindEnv("infoblox.http_request_timeout")
-	viper.SetDefault("infoblox.http_pool_connections", 10)
-	viper.BindEnv("infoblox.http_pool_connections")
+	v.SetDefault("infoblox.master", "")
+	v.BindEnv("infoblox.master")
+	v.SetDefault("infoblox.master_port", "")
+	v.BindEnv("infoblox.master_port")
+	v.SetDefault("infoblox.wapi_version", "")
+	v.BindEnv("infoblox.wapi_version")
+	v.SetDefault("infoblox.username", "")
+	v.BindEnv("infoblox.username")
+	v.SetDefault("infoblox.password", "")
+	v.BindEnv("infoblox.password")
+	v.BindEnv("infoblox.password_file")
+	v.SetDefault("infoblox.ssl_verify", false)
+	v.BindEnv("infoblox.ssl_verify")
+	v.SetDefault("infoblox.http_request_timeout", 20)
+	v.BindEnv("infoblox.http_request_timeout")
+	v.SetDefault("infoblox.http_pool_connections", 10)
+	v.BindEnv("infoblox.http_pool_connections")
+	v.SetDefault("infoblox.page_size", 1000)
+	v.BindEnv("infoblox.page_size")
+	v.SetDefault("infoblox.max_concurrent_requests", 10)
+	v.BindEnv("infoblox.max_concurrent_requests")
+	v.SetDefault("infoblox.max_queued_requests", 100)
+	v.BindEnv("infoblox.max_queued_requests")
+	v.SetDefault("infoblox.requests_per_second", 0)
+	v.BindEnv("infoblox.requests_per_second")
+	v.SetDefault("infoblox.circuit_breaker.max_failures", 5)
+	v.BindEnv("infoblox.circuit_breaker.max_failures")
+	v.SetDefault("infoblox.circuit_breaker.open_seconds", 30)
+	v.BindEnv("infoblox.circuit_breaker.open_seconds")
+	v.SetDefault("infoblox.client_cert_file", "")
+	v.BindEnv("infoblox.client_cert_file")
+	v.SetDefault("infoblox.client_key_file", "")
+	v.BindEnv("infoblox.client_key_file")
+	v.SetDefault("infoblox.ca_file", "")
+	v.BindEnv("infoblox.ca_file")
+
+	// Modules
+	v.SetDefault("modules.stale_records.days", []int{30, 90, 365})
+	v.SetDefault("modules.threat_protection.rule_type_zones", []string{})
 
 }
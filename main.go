@@ -22,7 +22,9 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -34,6 +36,8 @@ import (
 	"github.com/urfave/negroni"
 
 	"go-infoblox-exporter/probes"
+	"go-infoblox-exporter/secrets"
+	"go-infoblox-exporter/settings"
 )
 
 var version = "undefined"
@@ -45,7 +49,7 @@ func main() {
 		flag.PrintDefaults()
 	}
 
-	SetDefaultValues()
+	SetDefaultValues(viper.GetViper())
 
 	flag.Int("p", viper.GetInt("exporter.port"), "The port to start on")
 
@@ -56,6 +60,7 @@ func main() {
 	usage := flag.Bool("u", false, "Show usage")
 	versionFlag := flag.Bool("v", false, "Show version")
 	writeConfig := flag.Bool("default", false, "Write default config")
+	checkConfig := flag.Bool("check-config", false, "Validate the config file and the connection to the grid and exit")
 
 	flag.Parse()
 
@@ -111,15 +116,25 @@ func main() {
 
 	// Find and read the config file
 	err = viper.ReadInConfig()
+	if *checkConfig {
+		os.Exit(checkConfiguration(err))
+	}
 	if err != nil {
 		log.WithFields(log.Fields{"error": err}).Error("Configuration file not valid")
 		os.Exit(1)
 	}
 
 	// create a single instance of InfobloxApi to be reused for all requests
-	infobloxApi := probes.NewInfobloxApi()
+	infobloxApi, err := probes.NewInfobloxApi(viper.GetViper())
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("Infoblox configuration not valid")
+		os.Exit(1)
+	}
 	// Pass infobloxApi to handlers or set in probes package
 	probes.SetInfobloxApi(infobloxApi)
+	reloadSuccessGauge.Set(1)
+	reloadTimestampGauge.SetToCurrentTime()
+	watchReloadSignal()
 
 	// Create a Prometheus histogram for response time of the exporter
 	responseTime := promauto.NewHistogramVec(prometheus.HistogramOpts{
@@ -132,9 +147,15 @@ func main() {
 	http.Handle("/alive",
 		logCall(promMonitor(http.HandlerFunc(alive), responseTime, "/alive")))
 
+	http.Handle("/ready",
+		logCall(promMonitor(http.HandlerFunc(readyHandler), responseTime, "/ready")))
+
 	http.Handle("/probe",
 		logCall(promMonitor(basicAuth(http.HandlerFunc(ProbeHandler)), responseTime, "/probe")))
 
+	http.Handle("/-/reload",
+		logCall(promMonitor(basicAuth(http.HandlerFunc(reloadHandler)), responseTime, "/-/reload")))
+
 	http.Handle("/metrics", promhttp.Handler())
 
 	log.Info(fmt.Sprintf("%s starting on port %d", ExporterName, viper.GetInt("exporter."+
@@ -144,7 +165,33 @@ func main() {
 		WriteTimeout: 10 * time.Second,
 		Addr:         ":" + strconv.Itoa(viper.GetInt("exporter.port")),
 	}
-	log.Fatal(s.ListenAndServe())
+
+	tlsConfig := NewTLSConfiguration()
+	if tlsConfig.Enabled() {
+		s.TLSConfig, err = NewServerTLSConfig(tlsConfig)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err}).Error("TLS configuration not valid")
+			os.Exit(1)
+		}
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		if tlsConfig.Enabled() {
+			serverErr <- s.ListenAndServeTLS("", "")
+			return
+		}
+		serverErr <- s.ListenAndServe()
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-serverErr:
+		log.Fatal(err)
+	case sig := <-stop:
+		shutdown(s, sig)
+	}
 }
 
 type loggingResponseWriter struct {
@@ -196,7 +243,7 @@ func promMonitor(next http.Handler, ops *prometheus.HistogramVec, endpoint strin
 func basicAuth(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check if basic auth should be used
-		if !viper.IsSet("exporter.basic_auth") {
+		if !settings.Get().IsSet("exporter.basic_auth") {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -207,8 +254,14 @@ func basicAuth(next http.HandlerFunc) http.HandlerFunc {
 			// usernames and passwords.
 			usernameHash := sha256.Sum256([]byte(username))
 			passwordHash := sha256.Sum256([]byte(password))
-			expectedUsernameHash := sha256.Sum256([]byte(viper.GetString("exporter.basic_auth.username")))
-			expectedPasswordHash := sha256.Sum256([]byte(viper.GetString("exporter.basic_auth.password")))
+			expectedUsernameHash := sha256.Sum256([]byte(settings.Get().GetString("exporter.basic_auth.username")))
+			expectedPassword, err := secrets.Lookup("exporter.basic_auth.password").Secret()
+			if err != nil {
+				log.WithFields(log.Fields{"error": err}).Error("Failed to get basic auth password")
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			expectedPasswordHash := sha256.Sum256([]byte(expectedPassword))
 
 			// Use the subtle.ConstantTimeCompare() function to check if
 			// the provided username and password hashes equal the
@@ -46,7 +46,6 @@ func ProbeHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(30)*time.Second)
 	defer cancel()
 	registry := prometheus.NewRegistry()
-	registry.MustRegister(probeSuccessGauge)
 	registry.MustRegister(probeDurationGauge)
 
 	start := time.Now()
@@ -69,6 +68,18 @@ func ProbeHandler(w http.ResponseWriter, r *http.Request) {
 		probeSuccessGauge.Set(0)
 	}
 
+	// A probe that fail fast, like when the circuit breaker is open or the limiter reject a WAPI
+	// request, has the reason as a label
+	successGauge := probeSuccessGauge
+	if reason := pc.FailureReason(); reason != "" {
+		successGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "probe_success",
+			Help:        "Probe call success (1=Up,0=Down)",
+			ConstLabels: prometheus.Labels{"reason": reason},
+		})
+	}
+	registry.MustRegister(successGauge)
+
 	h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
 	h.ServeHTTP(w, r)
 }
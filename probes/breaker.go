@@ -0,0 +1,162 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker open, grid master not available")
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half_open"
+	}
+	return "closed"
+}
+
+var circuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: exporterPrefix + "_circuit_breaker_state",
+	Help: "State of the circuit breaker for the grid master (0=Closed, 1=Open, 2=Half open)",
+},
+	[]string{"master"},
+)
+
+// circuitBreaker stop requests to the grid master after maxFailures consecutive failures. When open
+// for openTimeout a single trial request is let through, half open, and if it succeed the breaker
+// is closed again.
+type circuitBreaker struct {
+	master      string
+	maxFailures int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// newCircuitBreaker create a breaker, maxFailures 0 disable the breaker
+func newCircuitBreaker(master string, maxFailures int, openTimeout time.Duration) *circuitBreaker {
+	breaker := &circuitBreaker{
+		master:      master,
+		maxFailures: maxFailures,
+		openTimeout: openTimeout,
+	}
+	circuitBreakerState.WithLabelValues(master).Set(float64(breakerClosed))
+	return breaker
+}
+
+// allow return ErrCircuitOpen if the request should fail fast
+func (b *circuitBreaker) allow() error {
+	if b.maxFailures <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return ErrCircuitOpen
+		}
+		b.setState(breakerHalfOpen)
+		return nil
+	case breakerHalfOpen:
+		// Only the trial request is let through
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// isOpen report if requests will fail fast, without changing the state
+func (b *circuitBreaker) isOpen() bool {
+	if b.maxFailures <= 0 {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state == breakerHalfOpen || (b.state == breakerOpen && time.Since(b.openedAt) < b.openTimeout)
+}
+
+// record the result of a request that was allowed
+func (b *circuitBreaker) record(err error) {
+	if b.maxFailures <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isGridFailure(err) {
+		b.failures = 0
+		if b.state != breakerClosed {
+			b.setState(breakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || (b.state == breakerClosed && b.failures >= b.maxFailures) {
+		b.openedAt = time.Now()
+		b.setState(breakerOpen)
+	}
+}
+
+func (b *circuitBreaker) setState(state breakerState) {
+	log.WithFields(log.Fields{"master": b.master, "from": b.state.String(), "to": state.String(),
+		"failures": b.failures}).Info("Circuit breaker state changed")
+	b.state = state
+	circuitBreakerState.WithLabelValues(b.master).Set(float64(state))
+}
+
+// isGridFailure report if the error means the grid master is not available, a connection error
+// or a 5xx response. Other errors, like an object not found, mean the grid master answered.
+func isGridFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	return strings.HasPrefix(err.Error(), "WAPI request error: 5")
+}
@@ -0,0 +1,147 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+)
+
+var errConnection = &url.Error{Op: "Get", URL: "https://127.0.0.1/wapi/v2.10.5/grid", Err: errors.New("connection refused")}
+
+func TestBreakerOpenAfterMaxFailures(t *testing.T) {
+	b := newCircuitBreaker("breaker-open", 2, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if err := b.allow(); err != nil {
+			t.Fatalf("request %d not allowed: %v", i, err)
+		}
+		b.record(errConnection)
+	}
+	if b.state != breakerOpen {
+		t.Fatalf("state %s, want open", b.state)
+	}
+	if !b.isOpen() {
+		t.Error("isOpen false for an open breaker")
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("allow returned %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestBreakerIgnoreNonGridFailures(t *testing.T) {
+	b := newCircuitBreaker("breaker-not-found", 1, time.Minute)
+
+	b.record(errors.New("WAPI request error: 404('404 Not Found')"))
+	if b.state != breakerClosed {
+		t.Errorf("state %s after a 404, want closed", b.state)
+	}
+	b.record(errors.New("WAPI request error: 503('503 Service Unavailable')"))
+	if b.state != breakerOpen {
+		t.Errorf("state %s after a 503, want open", b.state)
+	}
+}
+
+func TestBreakerSuccessResetFailures(t *testing.T) {
+	b := newCircuitBreaker("breaker-reset", 2, time.Minute)
+
+	b.record(errConnection)
+	b.record(nil)
+	b.record(errConnection)
+	if b.state != breakerClosed {
+		t.Errorf("state %s, want closed since the failures are not consecutive", b.state)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b := newCircuitBreaker("breaker-half-open", 1, 10*time.Millisecond)
+	b.record(errConnection)
+	time.Sleep(20 * time.Millisecond)
+
+	// A single trial request is let through when the open timeout has passed
+	if err := b.allow(); err != nil {
+		t.Fatalf("trial request not allowed: %v", err)
+	}
+	if b.state != breakerHalfOpen {
+		t.Fatalf("state %s, want half_open", b.state)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second request in half open returned %v, want ErrCircuitOpen", err)
+	}
+
+	// A failed trial open the breaker again
+	b.record(errConnection)
+	if b.state != breakerOpen {
+		t.Fatalf("state %s after a failed trial, want open", b.state)
+	}
+
+	// A successful trial close the breaker
+	time.Sleep(20 * time.Millisecond)
+	if err := b.allow(); err != nil {
+		t.Fatalf("trial request not allowed: %v", err)
+	}
+	b.record(nil)
+	if b.state != breakerClosed {
+		t.Errorf("state %s after a successful trial, want closed", b.state)
+	}
+	if err := b.allow(); err != nil {
+		t.Errorf("request not allowed by a closed breaker: %v", err)
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := newCircuitBreaker("breaker-disabled", 0, time.Minute)
+
+	for i := 0; i < 10; i++ {
+		b.record(errConnection)
+	}
+	if err := b.allow(); err != nil {
+		t.Errorf("disabled breaker returned %v", err)
+	}
+	if b.isOpen() {
+		t.Error("disabled breaker is open")
+	}
+}
+
+func TestIsGridFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"connection", errConnection, true},
+		{"wrapped connection", fmt.Errorf("failed to get grid: %w", errConnection), true},
+		{"dial", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{"server error", errors.New("WAPI request error: 503('503 Service Unavailable')"), true},
+		{"internal server error", errors.New("WAPI request error: 500('500 Internal Server Error')"), true},
+		{"unauthorized", errors.New("WAPI request error: 401('401 Unauthorized')"), false},
+		{"bad request", errors.New("WAPI request error: 400('400 Bad Request')"), false},
+		{"not found", errors.New("not found"), false},
+		{"circuit open", ErrCircuitOpen, false},
+		{"queue full", ErrQueueFull, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isGridFailure(test.err); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var prefixCapacity = fmt.Sprintf("%s_%s", prefixMember, "capacity")
+var capacityLabels = []string{"hwtype", "platform"}
+var capacityObjectLabels = []string{"hwtype", "platform", "type_name"}
+
+var (
+	capacityUsed = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixCapacity, "used_ratio"),
+		"Ratio of the member database capacity in use",
+		capacityLabels, nil,
+	)
+	capacityMax = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixCapacity, "max_objects"),
+		"Maximum number of objects supported by the member platform",
+		capacityLabels, nil,
+	)
+	capacityTotal = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixCapacity, "total_objects"),
+		"Total number of objects on the member",
+		capacityLabels, nil,
+	)
+	capacityObjects = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixCapacity, "objects"),
+		"Number of objects on the member per object type",
+		capacityObjectLabels, nil,
+	)
+)
+
+func probeCapacity(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	member, err := api.GetMember(target)
+	if err != nil {
+		return m, false
+	}
+
+	report, err := api.GetCapacityReport(target)
+	if err != nil {
+		return m, false
+	}
+
+	m = metricsCapacity(member, report, m)
+
+	return m, true
+}
+
+func metricsCapacity(member Member, report CapacityReport, m []prometheus.Metric) []prometheus.Metric {
+
+	// Use the same hwtype and platform as infoblox_member_node_info, the capacity
+	// report hardware type is only used if the member has no node info
+	hwtype := report.HardwareType
+	platform := ""
+	if len(member.Nodeinfo) > 0 {
+		hwtype = member.Nodeinfo[0].Hwtype
+		platform = member.Nodeinfo[0].Hwplatform
+	}
+
+	m = append(m, prometheus.MustNewConstMetric(capacityUsed, prometheus.GaugeValue, float64(report.PercentUsed)/100.0,
+		hwtype, platform))
+	m = append(m, prometheus.MustNewConstMetric(capacityMax, prometheus.GaugeValue, float64(report.MaxCapacity),
+		hwtype, platform))
+	m = append(m, prometheus.MustNewConstMetric(capacityTotal, prometheus.GaugeValue, float64(report.TotalObjects),
+		hwtype, platform))
+
+	dup := make(map[string]string)
+	for _, obj := range report.ObjectCounts {
+		_, ok := dup[obj.TypeName]
+		if ok {
+			continue
+		} else {
+			dup[obj.TypeName] = obj.TypeName
+		}
+		m = append(m, prometheus.MustNewConstMetric(capacityObjects, prometheus.GaugeValue, float64(obj.Count),
+			hwtype, platform, obj.TypeName))
+	}
+
+	return m
+}
@@ -0,0 +1,82 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var prefixCertificate = fmt.Sprintf("%s_%s", prefix, "certificate")
+var certificateLabels = []string{"source", "subject", "issuer", "serial"}
+
+var (
+	certificateNotAfter = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixCertificate, "expiry_timestamp_seconds"),
+		"Certificate expiry as unix timestamp in seconds",
+		certificateLabels, nil,
+	)
+	certificateNotBefore = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixCertificate, "not_before_timestamp_seconds"),
+		"Certificate validity start as unix timestamp in seconds",
+		certificateLabels, nil,
+	)
+)
+
+func probeCertificates(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	master, err := api.GetMasterCertificate()
+	if err != nil {
+		return m, false
+	}
+
+	certs, err := api.GetX509Certificates()
+	if err != nil {
+		return m, false
+	}
+
+	m = append(m, prometheus.MustNewConstMetric(certificateNotAfter, prometheus.GaugeValue,
+		float64(master.NotAfter.Unix()), "https", master.Subject.String(), master.Issuer.String(),
+		master.SerialNumber.String()))
+	m = append(m, prometheus.MustNewConstMetric(certificateNotBefore, prometheus.GaugeValue,
+		float64(master.NotBefore.Unix()), "https", master.Subject.String(), master.Issuer.String(),
+		master.SerialNumber.String()))
+
+	m = metricsCertificates(certs, m)
+
+	return m, true
+}
+
+func metricsCertificates(certs []X509Certificate, m []prometheus.Metric) []prometheus.Metric {
+
+	dup := make(map[string]string)
+	for _, cert := range certs {
+		key := cert.Subject + "/" + cert.Issuer + "/" + cert.Serial
+		_, ok := dup[key]
+		if ok {
+			continue
+		} else {
+			dup[key] = key
+		}
+		m = append(m, prometheus.MustNewConstMetric(certificateNotAfter, prometheus.GaugeValue,
+			float64(cert.ValidNotAfter), "grid", cert.Subject, cert.Issuer, cert.Serial))
+		m = append(m, prometheus.MustNewConstMetric(certificateNotBefore, prometheus.GaugeValue,
+			float64(cert.ValidNotBefore), "grid", cert.Subject, cert.Issuer, cert.Serial))
+	}
+
+	return m
+}
@@ -0,0 +1,178 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+
+	ibclient "github.com/infobloxopen/infoblox-go-client/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var prefixConsistency = fmt.Sprintf("%s_%s", prefix, "dhcp_failover")
+var consistencyLabels = []string{"category"}
+
+var (
+	consistencyMismatches = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixConsistency, "mismatches"),
+		"Number of configuration mismatches between the failover peers",
+		consistencyLabels, nil,
+	)
+)
+
+func probeConsistency(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	failover, err := api.GetDhcpFailover(target)
+	if err != nil {
+		return m, false
+	}
+
+	// Only the ranges in the networks of the failover association are requested, not all ranges
+	// of the grid
+	failoverRanges, err := api.GetFailoverRanges(failover.Name)
+	if err != nil {
+		return m, false
+	}
+	var ranges []Range
+	for _, network := range rangeNetworks(failoverRanges) {
+		networkRanges, err := api.GetRanges(network)
+		if err != nil {
+			return m, false
+		}
+		ranges = append(ranges, networkRanges...)
+	}
+
+	// Member DHCP properties only exist for peers that are grid members
+	if failover.PrimaryServerType == "INTERNAL" && failover.SecondaryServerType == "INTERNAL" {
+		primary, err := api.GetMemberDhcpProperties(failover.Primary)
+		if err != nil {
+			return m, false
+		}
+
+		secondary, err := api.GetMemberDhcpProperties(failover.Secondary)
+		if err != nil {
+			return m, false
+		}
+
+		m = append(m, prometheus.MustNewConstMetric(consistencyMismatches, prometheus.GaugeValue,
+			float64(optionMismatches(primary.Options, secondary.Options)), "options"))
+		m = append(m, prometheus.MustNewConstMetric(consistencyMismatches, prometheus.GaugeValue,
+			float64(propertyMismatches(primary, secondary)), "properties"))
+	}
+
+	peerRanges, assignments := rangeMismatches(failover, ranges)
+	m = append(m, prometheus.MustNewConstMetric(consistencyMismatches, prometheus.GaugeValue,
+		float64(peerRanges), "ranges"))
+	m = append(m, prometheus.MustNewConstMetric(consistencyMismatches, prometheus.GaugeValue,
+		float64(assignments), "failover_assignment"))
+
+	return m, true
+}
+
+// rangeNetworks return the networks of the ranges, each network once
+func rangeNetworks(ranges []Range) []string {
+	var networks []string
+	seen := make(map[string]bool)
+	for _, r := range ranges {
+		if !seen[r.Cidr] {
+			seen[r.Cidr] = true
+			networks = append(networks, r.Cidr)
+		}
+	}
+	return networks
+}
+
+// optionMismatches count the DHCP options that only exist on one of the peers or have different values
+func optionMismatches(primary []ibclient.Dhcpoption, secondary []ibclient.Dhcpoption) int {
+	key := func(opt ibclient.Dhcpoption) string {
+		return fmt.Sprintf("%s/%d/%s", opt.VendorClass, opt.Num, opt.Name)
+	}
+
+	values := make(map[string]string)
+	for _, opt := range primary {
+		values[key(opt)] = opt.Value
+	}
+
+	mismatches := 0
+	for _, opt := range secondary {
+		value, ok := values[key(opt)]
+		if !ok || value != opt.Value {
+			mismatches++
+		}
+		delete(values, key(opt))
+	}
+
+	return mismatches + len(values)
+}
+
+// propertyMismatches count the member DHCP properties that differ between the peers
+func propertyMismatches(primary MemberDhcpProperties, secondary MemberDhcpProperties) int {
+	mismatches := 0
+	if primary.EnableDhcp != secondary.EnableDhcp {
+		mismatches++
+	}
+	if primary.Authority != secondary.Authority {
+		mismatches++
+	}
+	if primary.EnableDdns != secondary.EnableDdns {
+		mismatches++
+	}
+	if primary.PingCount != secondary.PingCount {
+		mismatches++
+	}
+	if primary.PingTimeout != secondary.PingTimeout {
+		mismatches++
+	}
+	if primary.LeaseScavengeTime != secondary.LeaseScavengeTime {
+		mismatches++
+	}
+	if primary.RecycleLeases != secondary.RecycleLeases {
+		mismatches++
+	}
+	return mismatches
+}
+
+// rangeMismatches compare the ranges the peers serve in the networks of the failover association.
+// A range assigned directly to one of the peers is only served by that peer, and a failover range
+// that point to another failover association is not served by this association. Ranges in networks
+// without ranges of the failover association, like ranges of a single member, are not compared.
+func rangeMismatches(failover DhcpFailover, ranges []Range) (int, int) {
+	failoverNetworks := make(map[string]bool)
+	for _, r := range ranges {
+		if r.ServerAssociationType == "FAILOVER" && r.FailoverAssociation == failover.Name {
+			failoverNetworks[r.Cidr] = true
+		}
+	}
+
+	peerRanges := 0
+	assignments := 0
+	for _, r := range ranges {
+		if !failoverNetworks[r.Cidr] {
+			continue
+		}
+		switch r.ServerAssociationType {
+		case "MEMBER":
+			if r.Member != nil && (r.Member.Name == failover.Primary || r.Member.Name == failover.Secondary) {
+				peerRanges++
+			}
+		case "FAILOVER":
+			if r.FailoverAssociation != failover.Name {
+				assignments++
+			}
+		}
+	}
+	return peerRanges, assignments
+}
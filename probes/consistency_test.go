@@ -0,0 +1,154 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"testing"
+
+	ibclient "github.com/infobloxopen/infoblox-go-client/v2"
+)
+
+func TestRangeMismatches(t *testing.T) {
+	failover := DhcpFailover{Name: "failover-1", Primary: "dhcp1.foo.com", PrimaryServerType: "INTERNAL",
+		Secondary: "dhcp2.foo.com", SecondaryServerType: "INTERNAL"}
+
+	failoverRange := func(network string, association string) Range {
+		return Range{Cidr: network, ServerAssociationType: "FAILOVER", FailoverAssociation: association}
+	}
+	memberRange := func(network string, member string) Range {
+		return Range{Cidr: network, ServerAssociationType: "MEMBER", Member: &ibclient.Dhcpmember{Name: member}}
+	}
+
+	tests := []struct {
+		name            string
+		ranges          []Range
+		wantPeerRanges  int
+		wantAssignments int
+	}{
+		{
+			name: "all ranges served by the failover association",
+			ranges: []Range{
+				failoverRange("10.0.0.0/24", "failover-1"),
+				failoverRange("10.0.1.0/24", "failover-1"),
+			},
+		},
+		{
+			name: "range served by one peer in a failover network",
+			ranges: []Range{
+				failoverRange("10.0.0.0/24", "failover-1"),
+				memberRange("10.0.0.0/24", "dhcp1.foo.com"),
+				memberRange("10.0.0.0/24", "dhcp2.foo.com"),
+			},
+			wantPeerRanges: 2,
+		},
+		{
+			name: "single member ranges in other networks are not drift",
+			ranges: []Range{
+				failoverRange("10.0.0.0/24", "failover-1"),
+				memberRange("10.0.5.0/24", "dhcp1.foo.com"),
+				memberRange("10.0.6.0/24", "dhcp3.foo.com"),
+			},
+		},
+		{
+			name: "range of another member in a failover network",
+			ranges: []Range{
+				failoverRange("10.0.0.0/24", "failover-1"),
+				memberRange("10.0.0.0/24", "dhcp3.foo.com"),
+			},
+		},
+		{
+			name: "range assigned to another failover association",
+			ranges: []Range{
+				failoverRange("10.0.0.0/24", "failover-1"),
+				failoverRange("10.0.0.0/24", "failover-2"),
+				failoverRange("10.0.7.0/24", "failover-2"),
+			},
+			wantAssignments: 1,
+		},
+		{
+			name: "no ranges",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			peerRanges, assignments := rangeMismatches(failover, test.ranges)
+			if peerRanges != test.wantPeerRanges {
+				t.Errorf("peer ranges %d, want %d", peerRanges, test.wantPeerRanges)
+			}
+			if assignments != test.wantAssignments {
+				t.Errorf("failover assignments %d, want %d", assignments, test.wantAssignments)
+			}
+		})
+	}
+}
+
+func TestRangeNetworks(t *testing.T) {
+	networks := rangeNetworks([]Range{{Cidr: "10.0.0.0/24"}, {Cidr: "10.0.1.0/24"}, {Cidr: "10.0.0.0/24"}})
+	if len(networks) != 2 || networks[0] != "10.0.0.0/24" || networks[1] != "10.0.1.0/24" {
+		t.Errorf("got %v, want [10.0.0.0/24 10.0.1.0/24]", networks)
+	}
+}
+
+func TestOptionMismatches(t *testing.T) {
+	router := ibclient.Dhcpoption{Name: "routers", Num: 3, Value: "10.0.0.1"}
+	dns := ibclient.Dhcpoption{Name: "domain-name-servers", Num: 6, Value: "10.0.0.53"}
+	otherDns := ibclient.Dhcpoption{Name: "domain-name-servers", Num: 6, Value: "10.0.0.54"}
+
+	tests := []struct {
+		name      string
+		primary   []ibclient.Dhcpoption
+		secondary []ibclient.Dhcpoption
+		want      int
+	}{
+		{"equal", []ibclient.Dhcpoption{router, dns}, []ibclient.Dhcpoption{dns, router}, 0},
+		{"different value", []ibclient.Dhcpoption{router, dns}, []ibclient.Dhcpoption{router, otherDns}, 1},
+		{"only on primary", []ibclient.Dhcpoption{router, dns}, []ibclient.Dhcpoption{router}, 1},
+		{"only on secondary", nil, []ibclient.Dhcpoption{router, dns}, 2},
+		{"none", nil, nil, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := optionMismatches(test.primary, test.secondary); got != test.want {
+				t.Errorf("got %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPropertyMismatches(t *testing.T) {
+	primary := MemberDhcpProperties{EnableDhcp: true, Authority: true, PingCount: 1, PingTimeout: 1000,
+		LeaseScavengeTime: -1}
+
+	tests := []struct {
+		name      string
+		secondary MemberDhcpProperties
+		want      int
+	}{
+		{"equal", primary, 0},
+		{"authority", MemberDhcpProperties{EnableDhcp: true, PingCount: 1, PingTimeout: 1000,
+			LeaseScavengeTime: -1}, 1},
+		{"ping and ddns", MemberDhcpProperties{EnableDhcp: true, Authority: true, EnableDdns: true,
+			PingCount: 2, PingTimeout: 1000, LeaseScavengeTime: -1}, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := propertyMismatches(primary, test.secondary); got != test.want {
+				t.Errorf("got %d, want %d", got, test.want)
+			}
+		})
+	}
+}
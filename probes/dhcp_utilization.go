@@ -29,11 +29,11 @@ var (
 	)
 )
 
-func probeDhcpUtilization(target string) ([]prometheus.Metric, bool) {
+func probeDhcpUtilization(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
 
 	var m []prometheus.Metric
 
-	utilization, err := infobloxApi.GetDhcpUtilization(target)
+	utilization, err := api.GetDhcpUtilization(target)
 	if err != nil {
 		return m, false
 	}
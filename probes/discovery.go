@@ -0,0 +1,93 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var prefixDiscovery = fmt.Sprintf("%s_%s", prefix, "discovery")
+var discoveryDeviceLabels = []string{"type"}
+
+var (
+	discoveryDevices = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixDiscovery, "devices"),
+		"Number of discovered devices in the network per device type",
+		discoveryDeviceLabels, nil,
+	)
+	discoveryLastDiscovered = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixDiscovery, "last_discovered_timestamp_seconds"),
+		"Last time an address in the network was discovered as unix timestamp in seconds",
+		nil, nil,
+	)
+	discoveryUnmanaged = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixDiscovery, "unmanaged_addresses"),
+		"Number of discovered addresses in the network that are not managed in IPAM",
+		nil, nil,
+	)
+)
+
+func probeDiscovery(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	devices, err := api.GetDiscoveryDevices(target)
+	if err != nil {
+		return m, false
+	}
+
+	addresses, err := api.GetUsedAddresses(target)
+	if err != nil {
+		return m, false
+	}
+
+	m = metricsDiscovery(devices, addresses, m)
+
+	return m, true
+}
+
+func metricsDiscovery(devices []DiscoveryDevice, addresses []Ipv4Address, m []prometheus.Metric) []prometheus.Metric {
+
+	types := make(map[string]int)
+	for _, device := range devices {
+		types[device.Type]++
+	}
+	for deviceType, count := range types {
+		m = append(m, prometheus.MustNewConstMetric(discoveryDevices, prometheus.GaugeValue, float64(count), deviceType))
+	}
+
+	unmanaged := 0
+	var lastDiscovered int64
+	for _, address := range addresses {
+		for _, t := range address.Types {
+			if t == "UNMANAGED" {
+				unmanaged++
+				break
+			}
+		}
+		if address.DiscoveredData != nil && address.DiscoveredData.LastDiscovered > lastDiscovered {
+			lastDiscovered = address.DiscoveredData.LastDiscovered
+		}
+	}
+	m = append(m, prometheus.MustNewConstMetric(discoveryUnmanaged, prometheus.GaugeValue, float64(unmanaged)))
+
+	if lastDiscovered > 0 {
+		m = append(m, prometheus.MustNewConstMetric(discoveryLastDiscovered, prometheus.GaugeValue,
+			float64(lastDiscovered)))
+	}
+
+	return m
+}
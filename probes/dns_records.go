@@ -0,0 +1,109 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var prefixDns = fmt.Sprintf("%s_%s", prefix, "dns")
+var dnsRecordLabels = []string{"zone", "view", "type"}
+var dnsHostAddressLabels = []string{"zone", "view"}
+
+var (
+	dnsRecords = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixDns, "records"),
+		"Number of DNS records in the zone per record type",
+		dnsRecordLabels, nil,
+	)
+	dnsHostAddresses = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixDns, "host_addresses"),
+		"Number of IPv4 and IPv6 addresses of the host records in the zone",
+		dnsHostAddressLabels, nil,
+	)
+)
+
+// hostAddressTypes are the allrecords types returned once for each address of a host record
+var hostAddressTypes = map[string]bool{
+	"host_ipv4addr": true,
+	"host_ipv6addr": true,
+}
+
+func probeDnsRecords(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	records, err := api.GetAllRecords(target)
+	if err != nil {
+		return m, false
+	}
+
+	m = metricsDnsRecords(records, m)
+
+	return m, true
+}
+
+func metricsDnsRecords(records []AllRecords, m []prometheus.Metric) []prometheus.Metric {
+
+	type recordKey struct {
+		zone       string
+		view       string
+		recordType string
+	}
+
+	type hostKey struct {
+		zone string
+		view string
+		name string
+	}
+
+	counts := make(map[recordKey]int)
+	addresses := make(map[recordKey]int)
+	hosts := make(map[hostKey]bool)
+	for _, rec := range records {
+		key := recordKey{
+			zone:       rec.Zone,
+			view:       rec.View,
+			recordType: strings.TrimPrefix(rec.Type, "record:"),
+		}
+
+		// A host record is counted once and not once per address
+		if hostAddressTypes[key.recordType] || key.recordType == "host" {
+			if hostAddressTypes[key.recordType] {
+				addresses[recordKey{zone: rec.Zone, view: rec.View}]++
+			}
+			host := hostKey{zone: rec.Zone, view: rec.View, name: rec.Name}
+			if hosts[host] {
+				continue
+			}
+			hosts[host] = true
+			key.recordType = "host"
+		}
+		counts[key]++
+	}
+
+	for key, count := range counts {
+		m = append(m, prometheus.MustNewConstMetric(dnsRecords, prometheus.GaugeValue, float64(count),
+			key.zone, key.view, key.recordType))
+	}
+	for key, count := range addresses {
+		m = append(m, prometheus.MustNewConstMetric(dnsHostAddresses, prometheus.GaugeValue, float64(count),
+			key.zone, key.view))
+	}
+
+	return m
+}
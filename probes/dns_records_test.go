@@ -0,0 +1,65 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMetricsDnsRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []AllRecords
+		want    map[string]float64
+	}{
+		{
+			name: "record types",
+			records: []AllRecords{
+				{Name: "www", Type: "record:a", View: "default", Zone: "foo.com"},
+				{Name: "ftp", Type: "record:a", View: "default", Zone: "foo.com"},
+				{Name: "mail", Type: "record:cname", View: "default", Zone: "foo.com"},
+			},
+			want: map[string]float64{
+				`infoblox_dns_records{type="a",view="default",zone="foo.com"}`:     2,
+				`infoblox_dns_records{type="cname",view="default",zone="foo.com"}`: 1,
+			},
+		},
+		{
+			name: "host counted once per record",
+			records: []AllRecords{
+				{Name: "srv1", Type: "record:host_ipv4addr", View: "default", Zone: "foo.com"},
+				{Name: "srv1", Type: "record:host_ipv4addr", View: "default", Zone: "foo.com"},
+				{Name: "srv1", Type: "record:host_ipv6addr", View: "default", Zone: "foo.com"},
+				{Name: "srv2", Type: "record:host_ipv4addr", View: "default", Zone: "foo.com"},
+				{Name: "srv2", Type: "record:host_ipv4addr", View: "internal", Zone: "foo.com"},
+			},
+			want: map[string]float64{
+				`infoblox_dns_records{type="host",view="default",zone="foo.com"}`:  2,
+				`infoblox_dns_records{type="host",view="internal",zone="foo.com"}`: 1,
+				`infoblox_dns_host_addresses{view="default",zone="foo.com"}`:       4,
+				`infoblox_dns_host_addresses{view="internal",zone="foo.com"}`:      1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := metricValues(t, metricsDnsRecords(test.records, nil))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var prefixDtc = fmt.Sprintf("%s_%s", prefix, "dtc")
+var dtcLabels = []string{"name"}
+var dtcServerLabels = []string{"name", "host"}
+
+var (
+	dtcServerHealth = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixDtc, "server_health"),
+		"DTC server availability (0=Red, 1=Green, 2=Gray, 3=Yellow)",
+		dtcServerLabels, nil,
+	)
+	dtcPoolHealth = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixDtc, "pool_health"),
+		"DTC pool availability (0=Red, 1=Green, 2=Gray, 3=Yellow)",
+		dtcLabels, nil,
+	)
+	dtcLbdnHealth = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixDtc, "lbdn_health"),
+		"DTC LBDN availability (0=Red, 1=Green, 2=Gray, 3=Yellow)",
+		dtcLabels, nil,
+	)
+)
+
+func probeDtc(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	servers, err := api.GetDtcServers()
+	if err != nil {
+		return m, false
+	}
+
+	pools, err := api.GetDtcPools()
+	if err != nil {
+		return m, false
+	}
+
+	lbdns, err := api.GetDtcLbdns()
+	if err != nil {
+		return m, false
+	}
+
+	m = metricsDtc(servers, pools, lbdns, m)
+
+	return m, true
+}
+
+func metricsDtc(servers []DtcServer, pools []DtcPool, lbdns []DtcLbdn, m []prometheus.Metric) []prometheus.Metric {
+
+	for _, server := range servers {
+		m = append(m, prometheus.MustNewConstMetric(dtcServerHealth, prometheus.GaugeValue,
+			getAvailability(server.Health.Availability), server.Name, server.Host))
+	}
+
+	for _, pool := range pools {
+		m = append(m, prometheus.MustNewConstMetric(dtcPoolHealth, prometheus.GaugeValue,
+			getAvailability(pool.Health.Availability), pool.Name))
+	}
+
+	for _, lbdn := range lbdns {
+		m = append(m, prometheus.MustNewConstMetric(dtcLbdnHealth, prometheus.GaugeValue,
+			getAvailability(lbdn.Health.Availability), lbdn.Name))
+	}
+
+	return m
+}
+
+func getAvailability(availability string) float64 {
+	if availability == "GREEN" {
+		return 1.0
+	} else if availability == "GRAY" {
+		return 2.0
+	} else if availability == "YELLOW" {
+		return 3.0
+	}
+	return 0.0
+}
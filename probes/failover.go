@@ -0,0 +1,117 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync/atomic"
+
+	ibclient "github.com/infobloxopen/infoblox-go-client/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+)
+
+var gridMasterInfo = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: exporterPrefix + "_grid_master_info",
+	Help: "The grid master address that the exporter is connected to",
+},
+	[]string{"master", "active_master"},
+)
+
+// masterFailover has a connector for each configured address of the grid master. If the active
+// address is not available the next address is tried, in the configured order, and remembered as
+// active if it answer, like when a grid master candidate is promoted.
+type masterFailover struct {
+	master    string
+	addresses []string
+	conns     []*ibclient.Connector
+	active    atomic.Int32
+	// used is set for the addresses that answered a request and may have a session to log out
+	used []atomic.Bool
+}
+
+func newMasterFailover(addresses []string, conns []*ibclient.Connector) *masterFailover {
+	failover := &masterFailover{
+		master:    addresses[0],
+		addresses: addresses,
+		conns:     conns,
+		used:      make([]atomic.Bool, len(conns)),
+	}
+	failover.setMetric(0)
+	return failover
+}
+
+func (f *masterFailover) activeAddress() string {
+	return f.addresses[f.active.Load()]
+}
+
+// getObject send the request to the active address and then the next addresses until one answer.
+// The ibclient retry a failed request once, so each address that is not available cost up to twice
+// the http_request_timeout. No more addresses are tried when the ctx deadline has passed.
+func (f *masterFailover) getObject(ctx context.Context, obj ibclient.IBObject, ref string,
+	queryParams *ibclient.QueryParams, res interface{}) error {
+	start := int(f.active.Load())
+
+	var err error
+	for n := 0; n < len(f.conns); n++ {
+		if n > 0 && ctx.Err() != nil {
+			break
+		}
+		index := (start + n) % len(f.conns)
+		err = f.conns[index].GetObject(obj, ref, queryParams, res)
+		var urlErr *url.Error
+		if !errors.As(err, &urlErr) {
+			f.used[index].Store(true)
+		}
+		if !isGridFailure(err) {
+			if index != start {
+				f.setActive(start, index)
+			}
+			return err
+		}
+		if len(f.conns) > 1 {
+			log.WithFields(log.Fields{"address": f.addresses[index], "error": err}).Warn(
+				"Grid master not available, trying next address")
+		}
+	}
+	return err
+}
+
+func (f *masterFailover) setActive(previous int, index int) {
+	if !f.active.CompareAndSwap(int32(previous), int32(index)) {
+		// Another request already changed the active address
+		return
+	}
+	log.WithFields(log.Fields{"master": f.master, "from": f.addresses[previous],
+		"to": f.addresses[index]}).Info("Grid master failover")
+	f.setMetric(index)
+}
+
+func (f *masterFailover) setMetric(index int) {
+	gridMasterInfo.DeletePartialMatch(prometheus.Labels{"master": f.master})
+	gridMasterInfo.WithLabelValues(f.master, f.addresses[index]).Set(1)
+}
+
+// logout from all addresses that answered a request, the active address may have changed by a
+// failover after a session was created on the previous address
+func (f *masterFailover) logout() {
+	for index, conn := range f.conns {
+		if f.used[index].Swap(false) {
+			conn.Logout()
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	ibclient "github.com/infobloxopen/infoblox-go-client/v2"
+)
+
+// failoverServer is a grid master address that count the requests it answered
+type failoverServer struct {
+	server   *httptest.Server
+	requests atomic.Int32
+}
+
+func newFailoverServer(t *testing.T) *failoverServer {
+	t.Helper()
+
+	s := &failoverServer{}
+	s.server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		_, _ = w.Write([]byte(`{"supported_versions": ["2.10.5"]}`))
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// newTestFailover create a failover of the servers, a closed server is an address that is not
+// available
+func newTestFailover(t *testing.T, master string, servers ...*failoverServer) *masterFailover {
+	t.Helper()
+
+	// The first address is the master label, like in the configuration
+	addresses := []string{master}
+	var conns []*ibclient.Connector
+	for index, s := range servers {
+		if index > 0 {
+			addresses = append(addresses, fmt.Sprintf("%s-%d", master, index))
+		}
+		conns = append(conns, newTestConnector(t, s.server.URL))
+	}
+	t.Cleanup(func() {
+		gridMasterInfo.DeletePartialMatch(map[string]string{"master": master})
+	})
+	return newMasterFailover(addresses, conns)
+}
+
+func getTestSchema(ctx context.Context, f *masterFailover) error {
+	var res WapiSchema
+	qp := ibclient.NewQueryParams(false, map[string]string{"_schema": "1"})
+	return f.getObject(ctx, NewWapiSchema(), "", qp, &res)
+}
+
+func TestFailoverOrder(t *testing.T) {
+	down, first, second := newFailoverServer(t), newFailoverServer(t), newFailoverServer(t)
+	down.server.Close()
+	f := newTestFailover(t, "failover-order", down, first, second)
+
+	// The next address in the configured order answer and is remembered as active
+	err := getTestSchema(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.active.Load() != 1 || first.requests.Load() != 1 || second.requests.Load() != 0 {
+		t.Errorf("got active %d, requests %d and %d, want the first available address",
+			f.active.Load(), first.requests.Load(), second.requests.Load())
+	}
+
+	// The active address is tried first
+	err = getTestSchema(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.requests.Load() != 2 {
+		t.Errorf("got %d requests to the active address, want 2", first.requests.Load())
+	}
+
+	// The addresses after the active address are tried before the addresses before it
+	first.server.Close()
+	err = getTestSchema(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.active.Load() != 2 || second.requests.Load() != 1 {
+		t.Errorf("got active %d, %d requests, want the second address", f.active.Load(), second.requests.Load())
+	}
+
+	// Only the addresses that answered has a session to log out
+	for index, want := range []bool{false, true, true} {
+		if f.used[index].Load() != want {
+			t.Errorf("address %d used %t, want %t", index, f.used[index].Load(), want)
+		}
+	}
+}
+
+func TestFailoverAllDown(t *testing.T) {
+	first, second := newFailoverServer(t), newFailoverServer(t)
+	first.server.Close()
+	second.server.Close()
+	f := newTestFailover(t, "failover-all-down", first, second)
+
+	err := getTestSchema(context.Background(), f)
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Errorf("got error %v, want the connection error", err)
+	}
+	if f.active.Load() != 0 {
+		t.Errorf("got active %d, want the active address kept", f.active.Load())
+	}
+}
+
+func TestFailoverDeadline(t *testing.T) {
+	down, up := newFailoverServer(t), newFailoverServer(t)
+	down.server.Close()
+	f := newTestFailover(t, "failover-deadline", down, up)
+
+	// The active address is always tried, no more addresses are tried when the deadline has passed
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := getTestSchema(ctx, f)
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Errorf("got error %v, want the connection error of the active address", err)
+	}
+	if up.requests.Load() != 0 || f.active.Load() != 0 {
+		t.Errorf("got %d requests to the next address and active %d, want no failover",
+			up.requests.Load(), f.active.Load())
+	}
+}
@@ -0,0 +1,126 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var prefixFixedAddress = fmt.Sprintf("%s_%s", prefix, "fixed_address")
+var fixedAddressLabels = []string{"match_client", "mac_set", "disabled"}
+
+// macZero is the mac address of a fixed address that is a reservation
+const macZero = "00:00:00:00:00:00"
+
+var (
+	fixedAddresses = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixFixedAddress, "count"),
+		"Number of fixed addresses in the network",
+		fixedAddressLabels, nil,
+	)
+	fixedAddressReservations = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixFixedAddress, "reservations"),
+		"Number of reservations in the network",
+		nil, nil,
+	)
+	fixedAddressInRange = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixFixedAddress, "in_range"),
+		"Number of fixed addresses that are inside a DHCP range",
+		nil, nil,
+	)
+)
+
+func probeFixedAddresses(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	fixed, err := api.GetFixedAddresses(target)
+	if err != nil {
+		return m, false
+	}
+
+	ranges, err := api.GetRanges(target)
+	if err != nil {
+		return m, false
+	}
+
+	m = metricsFixedAddresses(fixed, ranges, m)
+
+	return m, true
+}
+
+func metricsFixedAddresses(fixed []FixedAddress, ranges []Range, m []prometheus.Metric) []prometheus.Metric {
+
+	type fixedKey struct {
+		matchClient string
+		macSet      bool
+		disabled    bool
+	}
+
+	counts := make(map[fixedKey]int)
+	reservations := 0
+	inRange := 0
+	for _, fix := range fixed {
+		// In WAPI a reservation is a fixed address that match the zero mac address
+		if fix.MatchClient == "RESERVED" || fix.Mac == macZero {
+			reservations++
+		}
+
+		key := fixedKey{
+			matchClient: fix.MatchClient,
+			macSet:      fix.Mac != "" && fix.Mac != macZero,
+			disabled:    fix.Disable,
+		}
+		counts[key]++
+
+		if isInRange(fix.Ipv4Addr, ranges) {
+			inRange++
+		}
+	}
+
+	for key, count := range counts {
+		m = append(m, prometheus.MustNewConstMetric(fixedAddresses, prometheus.GaugeValue, float64(count),
+			key.matchClient, strconv.FormatBool(key.macSet), strconv.FormatBool(key.disabled)))
+	}
+	m = append(m, prometheus.MustNewConstMetric(fixedAddressReservations, prometheus.GaugeValue, float64(reservations)))
+	m = append(m, prometheus.MustNewConstMetric(fixedAddressInRange, prometheus.GaugeValue, float64(inRange)))
+
+	return m
+}
+
+func isInRange(address string, ranges []Range) bool {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+
+	for _, r := range ranges {
+		start, err := netip.ParseAddr(r.StartAddr)
+		if err != nil {
+			continue
+		}
+		end, err := netip.ParseAddr(r.EndAddr)
+		if err != nil {
+			continue
+		}
+		if addr.Compare(start) >= 0 && addr.Compare(end) <= 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var prefixGrid = fmt.Sprintf("%s_%s", prefix, "grid")
+var gridInfoLabels = []string{"name", "nios_version", "wapi_version"}
+var gridMemberUpgradeLabels = []string{"member", "current_version", "status", "upgrade_group"}
+var gridMemberLabels = []string{"member"}
+
+var (
+	gridInfo = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixGrid, "info"),
+		"Grid info",
+		gridInfoLabels, nil,
+	)
+	gridMemberUpgrade = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixGrid, "member_upgrade_info"),
+		"Upgrade status of the grid member",
+		gridMemberUpgradeLabels, nil,
+	)
+	gridVersionSkew = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixGrid, "version_skew_members"),
+		"Number of grid members with another major or minor NIOS version than the grid",
+		nil, nil,
+	)
+	gridMaster = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixGrid, "master"),
+		"Member that is the current grid master",
+		gridMemberLabels, nil,
+	)
+	gridMasterCandidate = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixGrid, "master_candidate"),
+		"Member is a grid master candidate (1=Candidate, 0=Not candidate)",
+		gridMemberLabels, nil,
+	)
+)
+
+func probeGrid(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	grid, err := api.GetGrid()
+	if err != nil {
+		return m, false
+	}
+
+	gridStatus, err := api.GetUpgradeStatus("GRID")
+	if err != nil {
+		return m, false
+	}
+
+	memberStatus, err := api.GetUpgradeStatus("VNODE")
+	if err != nil {
+		return m, false
+	}
+
+	members, err := api.GetMembers()
+	if err != nil {
+		return m, false
+	}
+
+	// The latest WAPI version supported by the grid, not the configured version
+	schema, err := api.GetSchema()
+	if err != nil {
+		return m, false
+	}
+
+	niosVersion := ""
+	if len(gridStatus) > 0 {
+		niosVersion = gridStatus[0].CurrentVersion
+	}
+	m = append(m, prometheus.MustNewConstMetric(gridInfo, prometheus.GaugeValue, 1.0,
+		grid.Name, niosVersion, latestVersion(schema.SupportedVersions)))
+
+	m = metricsGridUpgrade(niosVersion, memberStatus, m)
+	m = metricsGridMaster(api.Context(), api.ActiveMaster(), members, m)
+
+	return m, true
+}
+
+func metricsGridUpgrade(niosVersion string, memberStatus []UpgradeStatus, m []prometheus.Metric) []prometheus.Metric {
+
+	gridVersion := NewTargetMetadata(niosVersion)
+	skew := 0
+	dup := make(map[string]string)
+	for _, status := range memberStatus {
+		_, ok := dup[status.Member]
+		if ok {
+			continue
+		} else {
+			dup[status.Member] = status.Member
+		}
+		m = append(m, prometheus.MustNewConstMetric(gridMemberUpgrade, prometheus.GaugeValue, 1.0,
+			status.Member, status.CurrentVersion, status.StatusValue, status.UpgradeGroup))
+
+		memberVersion := NewTargetMetadata(status.CurrentVersion)
+		if memberVersion.VersionMajor != gridVersion.VersionMajor ||
+			memberVersion.VersionMinor != gridVersion.VersionMinor {
+			skew++
+		}
+	}
+	m = append(m, prometheus.MustNewConstMetric(gridVersionSkew, prometheus.GaugeValue, float64(skew)))
+
+	return m
+}
+
+// metricsGridMaster report the grid master as the member with the host name or VIP that the exporter
+// is connected to, the WAPI do not have a field for the current grid master
+func metricsGridMaster(ctx context.Context, master string, members []Member, m []prometheus.Metric) []prometheus.Metric {
+
+	addresses := map[string]bool{master: true}
+	ips, err := net.DefaultResolver.LookupHost(ctx, master)
+	if err == nil {
+		for _, ip := range ips {
+			addresses[ip] = true
+		}
+	}
+
+	for _, mem := range members {
+		if addresses[mem.HostName] || (mem.VipSetting != nil && addresses[mem.VipSetting.Address]) {
+			m = append(m, prometheus.MustNewConstMetric(gridMaster, prometheus.GaugeValue, 1.0, mem.HostName))
+		}
+
+		candidate := 0.0
+		if mem.MasterCandidate {
+			candidate = 1.0
+		}
+		m = append(m, prometheus.MustNewConstMetric(gridMasterCandidate, prometheus.GaugeValue, candidate,
+			mem.HostName))
+	}
+
+	return m
+}
+
+// latestVersion return the highest of the versions, like 2.12.3 of 2.9 and 2.12.3
+func latestVersion(versions []string) string {
+	latest := ""
+	for _, version := range versions {
+		if latest == "" || compareVersions(version, latest) > 0 {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// compareVersions compare dot separated versions by each number and return -1, 0 or 1
+func compareVersions(a string, b string) int {
+	partsA := strings.Split(a, ".")
+	partsB := strings.Split(b, ".")
+	for n := 0; n < len(partsA) || n < len(partsB); n++ {
+		var numA, numB int
+		if n < len(partsA) {
+			numA, _ = strconv.Atoi(partsA[n])
+		}
+		if n < len(partsB) {
+			numB, _ = strconv.Atoi(partsB[n])
+		}
+		if numA != numB {
+			if numA < numB {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -14,40 +14,162 @@
 package probes
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	ibclient "github.com/infobloxopen/infoblox-go-client/v2"
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+
+	"go-infoblox-exporter/secrets"
+	"go-infoblox-exporter/settings"
 )
 
-var infobloxApi InfoBloxApi
+// infobloxApi is swapped when the configuration is reloaded while probes are running. The api hold
+// the configuration it was created from, so the configuration and the api are replaced by the same
+// swap.
+var infobloxApi atomic.Pointer[InfoBloxApi]
+
+func init() {
+	settings.SetSource(func() *viper.Viper {
+		api := infobloxApi.Load()
+		if api == nil {
+			return nil
+		}
+		return api.settings
+	})
+}
+
+// SetInfobloxApi set the api used by the probes, and the running configuration to the configuration
+// of the api, and return the previous api, nil if not set before
+func SetInfobloxApi(api InfoBloxApi) *InfoBloxApi {
+	return infobloxApi.Swap(&api)
+}
+
+// GetInfobloxApi return the api used by the probes
+func GetInfobloxApi() *InfoBloxApi {
+	return infobloxApi.Load()
+}
+
+// AcquireInfobloxApi return the api used by the probes and a release function that must be called
+// when the caller is done with the api. A retired api is not logged out until it is released.
+func AcquireInfobloxApi() (*InfoBloxApi, func()) {
+	for {
+		api := infobloxApi.Load()
+		if api == nil {
+			return nil, func() {}
+		}
+		if api.users.acquire() {
+			return api, api.users.release
+		}
+		// The api was retired after the load, the api that replaced it is already set
+	}
+}
+
+// apiUsers count the users of an api so it is logged out first when the last user is done
+type apiUsers struct {
+	mu      sync.Mutex
+	count   int
+	retired bool
+	done    chan struct{}
+}
+
+func newApiUsers() *apiUsers {
+	return &apiUsers{done: make(chan struct{})}
+}
+
+func (u *apiUsers) acquire() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.retired {
+		return false
+	}
+	u.count++
+	return true
+}
+
+func (u *apiUsers) release() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.count--
+	if u.retired && u.count == 0 {
+		close(u.done)
+	}
+}
 
-func SetInfobloxApi(api InfoBloxApi) {
-	infobloxApi = api
+func (u *apiUsers) retire() <-chan struct{} {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if !u.retired {
+		u.retired = true
+		if u.count == 0 {
+			close(u.done)
+		}
+	}
+	return u.done
 }
 
 type InfoBloxConfiguration struct {
-	Master              string
-	Version             string
-	Port                int64
-	Username            string
-	Password            string
-	SSLVerify           bool
-	HTTPRequestTimeout  int
-	HTTPPoolConnections int
+	// Master is the first of the Masters and identify the grid in labels
+	Master                string
+	Masters               []string
+	Version               string
+	Port                  int64
+	Username              string
+	Password              secrets.Provider
+	SSLVerify             bool
+	HTTPRequestTimeout    int
+	HTTPPoolConnections   int
+	PageSize              int
+	ClientCertFile        string
+	ClientKeyFile         string
+	CAFile                string
+	MaxConcurrentRequests int
+	MaxQueuedRequests     int
+	RequestsPerSecond     int
+	BreakerMaxFailures    int
+	BreakerOpenSeconds    int
 }
 
-func NewInfoBloxConfiguration() InfoBloxConfiguration {
+func NewInfoBloxConfiguration(v *viper.Viper) InfoBloxConfiguration {
+	// The master is a single address or an ordered list of the master and master candidates
+	masters := v.GetStringSlice("infoblox.master")
+	master := ""
+	if len(masters) > 0 {
+		master = masters[0]
+	}
 	return InfoBloxConfiguration{
-		Master:              viper.GetString("infoblox.master"),
-		Version:             viper.GetString("infoblox.wapi_version"),
-		Port:                viper.GetInt64("infoblox.master_port"),
-		Username:            viper.GetString("infoblox.username"),
-		Password:            viper.GetString("infoblox.password"),
-		SSLVerify:           viper.GetBool("infoblox.ssl_verify"),
-		HTTPRequestTimeout:  viper.GetInt("infoblox.http_request_timeout"),
-		HTTPPoolConnections: viper.GetInt("infoblox.http_pool_connections"),
+		Master:                master,
+		Masters:               masters,
+		Version:               v.GetString("infoblox.wapi_version"),
+		Port:                  v.GetInt64("infoblox.master_port"),
+		Username:              v.GetString("infoblox.username"),
+		Password:              secrets.NewProvider(v, "infoblox.password"),
+		SSLVerify:             v.GetBool("infoblox.ssl_verify"),
+		HTTPRequestTimeout:    v.GetInt("infoblox.http_request_timeout"),
+		HTTPPoolConnections:   v.GetInt("infoblox.http_pool_connections"),
+		PageSize:              v.GetInt("infoblox.page_size"),
+		ClientCertFile:        v.GetString("infoblox.client_cert_file"),
+		ClientKeyFile:         v.GetString("infoblox.client_key_file"),
+		CAFile:                v.GetString("infoblox.ca_file"),
+		MaxConcurrentRequests: v.GetInt("infoblox.max_concurrent_requests"),
+		MaxQueuedRequests:     v.GetInt("infoblox.max_queued_requests"),
+		RequestsPerSecond:     v.GetInt("infoblox.requests_per_second"),
+		BreakerMaxFailures:    v.GetInt("infoblox.circuit_breaker.max_failures"),
+		BreakerOpenSeconds:    v.GetInt("infoblox.circuit_breaker.open_seconds"),
 	}
 }
 
@@ -61,6 +183,9 @@ type Member struct {
 	Nodeinfo                 []ibclient.Nodeinfo      `json:"node_info,omitempty"`
 	TimeZone                 string                   `json:"time_zone,omitempty"`
 	ServiceStatus            []ibclient.Servicestatus `json:"service_status,omitempty"`
+	NtpSetting               *ibclient.MemberNtp      `json:"ntp_setting,omitempty"`
+	VipSetting               *ibclient.SettingNetwork `json:"vip_setting,omitempty"`
+	MasterCandidate          bool                     `json:"master_candidate,omitempty"`
 }
 
 func (m *Member) ObjectType() string {
@@ -73,6 +198,91 @@ func NewMember(nodeName string) *Member {
 	}
 }
 
+type Grid struct {
+	ibclient.IBBase
+	Ref        string               `json:"_ref,omitempty"`
+	Name       string               `json:"name,omitempty"`
+	NtpSetting *ibclient.NTPSetting `json:"ntp_setting,omitempty"`
+}
+
+func (g *Grid) ObjectType() string {
+	return "grid"
+}
+
+func NewGrid() *Grid {
+	return &Grid{}
+}
+
+// WapiSchema is the schema of the WAPI, returned for the empty object type with _schema
+type WapiSchema struct {
+	ibclient.IBBase
+	RequestedVersion  string   `json:"requested_version,omitempty"`
+	SupportedVersions []string `json:"supported_versions,omitempty"`
+}
+
+func (w *WapiSchema) ObjectType() string {
+	return ""
+}
+
+func NewWapiSchema() *WapiSchema {
+	return &WapiSchema{}
+}
+
+type UpgradeStatus struct {
+	ibclient.IBBase
+	Ref            string `json:"_ref,omitempty"`
+	Type           string `json:"type,omitempty"`
+	Member         string `json:"member,omitempty"`
+	CurrentVersion string `json:"current_version,omitempty"`
+	StatusValue    string `json:"status_value,omitempty"`
+	UpgradeGroup   string `json:"upgrade_group,omitempty"`
+	UpgradeState   string `json:"upgrade_state,omitempty"`
+}
+
+func (u *UpgradeStatus) ObjectType() string {
+	return "upgradestatus"
+}
+
+func NewUpgradeStatus(statusType string) *UpgradeStatus {
+	return &UpgradeStatus{
+		Type: statusType,
+	}
+}
+
+type MemberDns struct {
+	ibclient.IBBase
+	Ref           string   `json:"_ref,omitempty"`
+	HostName      string   `json:"host_name,omitempty"`
+	Forwarders    []string `json:"forwarders,omitempty"`
+	ForwardOnly   bool     `json:"forward_only,omitempty"`
+	UseForwarders bool     `json:"use_forwarders,omitempty"`
+}
+
+func (d *MemberDns) ObjectType() string {
+	return "member:dns"
+}
+
+func NewMemberDns(nodeName string) *MemberDns {
+	return &MemberDns{
+		HostName: nodeName,
+	}
+}
+
+type GridDns struct {
+	ibclient.IBBase
+	Ref         string   `json:"_ref,omitempty"`
+	Forwarders  []string `json:"forwarders,omitempty"`
+	ForwardOnly bool     `json:"forward_only,omitempty"`
+}
+
+func (d *GridDns) ObjectType() string {
+	return "grid:dns"
+}
+
+func NewGridDns() *GridDns {
+	return &GridDns{}
+}
+
 type Range struct {
 	ibclient.IBBase
 	Ref         string      `json:"_ref,omitempty"`
@@ -80,6 +290,12 @@ type Range struct {
 	Ea          ibclient.EA `json:"extattrs"`
 	Comment     string      `json:"comment"`
 	Utilization int64       `json:"dhcp_utilization"`
+	StartAddr   string      `json:"start_addr,omitempty"`
+	EndAddr     string      `json:"end_addr,omitempty"`
+
+	ServerAssociationType string               `json:"server_association_type,omitempty"`
+	Member                *ibclient.Dhcpmember `json:"member,omitempty"`
+	FailoverAssociation   string               `json:"failover_association,omitempty"`
 }
 
 func (r *Range) ObjectType() string {
@@ -94,73 +310,1336 @@ func NewRange(cidr string, comment string, ea ibclient.EA) *Range {
 	}
 }
 
-type InfoBloxApi struct {
-	Conn *ibclient.Connector
+type FixedAddress struct {
+	ibclient.IBBase
+	Ref         string `json:"_ref,omitempty"`
+	Ipv4Addr    string `json:"ipv4addr,omitempty"`
+	Mac         string `json:"mac,omitempty"`
+	MatchClient string `json:"match_client,omitempty"`
+	Disable     bool   `json:"disable,omitempty"`
+	Network     string `json:"network,omitempty"`
 }
 
-func NewInfobloxApi() InfoBloxApi {
-	config := NewInfoBloxConfiguration()
+func (f *FixedAddress) ObjectType() string {
+	return "fixedaddress"
+}
 
-	hostConfig := ibclient.HostConfig{
-		Host:    config.Master,
-		Version: config.Version,
+func NewFixedAddress(cidr string) *FixedAddress {
+	return &FixedAddress{
+		Network: cidr,
 	}
+}
 
-	authConfig := ibclient.AuthConfig{
-		Username:   config.Username,
-		Password:   config.Password,
-		ClientCert: nil,
-		ClientKey:  nil,
+type AllRecords struct {
+	ibclient.IBBase
+	Ref  string `json:"_ref,omitempty"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+	View string `json:"view,omitempty"`
+	Zone string `json:"zone,omitempty"`
+}
+
+func (a *AllRecords) ObjectType() string {
+	return "allrecords"
+}
+
+func NewAllRecords(zone string) *AllRecords {
+	return &AllRecords{
+		Zone: zone,
 	}
-	transportConfig := ibclient.NewTransportConfig(strconv.FormatBool(config.SSLVerify), config.HTTPRequestTimeout,
-		config.HTTPPoolConnections)
-	requestBuilder := &ibclient.WapiRequestBuilder{}
-	requestor := &ibclient.WapiHttpRequestor{}
-	conn, err := ibclient.NewConnector(hostConfig, authConfig, transportConfig, requestBuilder, requestor)
-	if err != nil {
-		log.Error("Failed to connect", err)
+}
+
+type DnsRecord struct {
+	ibclient.IBBase
+	objectType  string
+	Ref         string `json:"_ref,omitempty"`
+	Name        string `json:"name,omitempty"`
+	View        string `json:"view,omitempty"`
+	Zone        string `json:"zone,omitempty"`
+	LastQueried int64  `json:"last_queried,omitempty"`
+}
+
+func (r *DnsRecord) ObjectType() string {
+	return r.objectType
+}
+
+func NewDnsRecord(recordType string, zone string) *DnsRecord {
+	return &DnsRecord{
+		objectType: recordType,
+		Zone:       zone,
 	}
+}
 
-	return InfoBloxApi{Conn: conn}
+// pagedResult is the response when the WAPI is called with _return_as_object
+type pagedResult[T any] struct {
+	Result     []T    `json:"result"`
+	NextPageId string `json:"next_page_id,omitempty"`
 }
 
-func (i InfoBloxApi) GetDhcpUtilization(network string) (Range, error) {
-	var res []Range
-	net := NewRange(network, "", nil)
+type DhcpFailover struct {
+	ibclient.IBBase
+	Ref                 string `json:"_ref,omitempty"`
+	Name                string `json:"name,omitempty"`
+	Primary             string `json:"primary,omitempty"`
+	PrimaryServerType   string `json:"primary_server_type,omitempty"`
+	Secondary           string `json:"secondary,omitempty"`
+	SecondaryServerType string `json:"secondary_server_type,omitempty"`
+}
 
-	queryAttribute := map[string]string{
-		"network":        network,
-		"_return_fields": "extattrs,network,dhcp_utilization,comment",
+func (d *DhcpFailover) ObjectType() string {
+	return "dhcpfailover"
+}
+
+func NewDhcpFailover(name string) *DhcpFailover {
+	return &DhcpFailover{
+		Name: name,
 	}
-	qp := ibclient.NewQueryParams(false, queryAttribute)
-	err := i.Conn.GetObject(net, "", qp, &res)
+}
 
-	if err != nil {
-		log.Error("Failed to get network", err)
-		return *net, err
+type MemberDhcpProperties struct {
+	ibclient.IBBase
+	Ref               string                `json:"_ref,omitempty"`
+	HostName          string                `json:"host_name,omitempty"`
+	Options           []ibclient.Dhcpoption `json:"options,omitempty"`
+	EnableDhcp        bool                  `json:"enable_dhcp,omitempty"`
+	Authority         bool                  `json:"authority,omitempty"`
+	EnableDdns        bool                  `json:"enable_ddns,omitempty"`
+	PingCount         int64                 `json:"ping_count,omitempty"`
+	PingTimeout       int64                 `json:"ping_timeout,omitempty"`
+	LeaseScavengeTime int64                 `json:"lease_scavenge_time,omitempty"`
+	RecycleLeases     bool                  `json:"recycle_leases,omitempty"`
+}
+
+func (d *MemberDhcpProperties) ObjectType() string {
+	return "member:dhcpproperties"
+}
+
+func NewMemberDhcpProperties(nodeName string) *MemberDhcpProperties {
+	return &MemberDhcpProperties{
+		HostName: nodeName,
 	}
+}
 
-	return res[0], nil
+type ScheduledTask struct {
+	ibclient.IBBase
+	Ref             string `json:"_ref,omitempty"`
+	TaskId          int64  `json:"task_id,omitempty"`
+	ApprovalStatus  string `json:"approval_status,omitempty"`
+	ExecutionStatus string `json:"execution_status,omitempty"`
+	SubmitTime      int64  `json:"submit_time,omitempty"`
+	Submitter       string `json:"submitter,omitempty"`
 }
 
-func (i InfoBloxApi) GetMember(nodeName string) (Member, error) {
-	var res []Member
-	net := NewMember(nodeName)
+func (t *ScheduledTask) ObjectType() string {
+	return "scheduledtask"
+}
 
-	queryAttribute := map[string]string{
-		"host_name":      nodeName,
-		"_return_fields": "extattrs,host_name,node_info,service_status",
+func NewScheduledTask() *ScheduledTask {
+	return &ScheduledTask{}
+}
+
+type AdminUser struct {
+	ibclient.IBBase
+	Ref         string   `json:"_ref,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	AdminGroups []string `json:"admin_groups,omitempty"`
+}
+
+func (a *AdminUser) ObjectType() string {
+	return "adminuser"
+}
+
+func NewAdminUser() *AdminUser {
+	return &AdminUser{}
+}
+
+type Ipv4Address struct {
+	ibclient.IBBase
+	Ref            string          `json:"_ref,omitempty"`
+	IpAddress      string          `json:"ip_address,omitempty"`
+	Network        string          `json:"network,omitempty"`
+	Status         string          `json:"status,omitempty"`
+	Types          []string        `json:"types,omitempty"`
+	DiscoveredData *DiscoveredData `json:"discovered_data,omitempty"`
+}
+
+type DiscoveredData struct {
+	LastDiscovered int64 `json:"last_discovered,omitempty"`
+}
+
+func (a *Ipv4Address) ObjectType() string {
+	return "ipv4address"
+}
+
+func NewIpv4Address(cidr string) *Ipv4Address {
+	return &Ipv4Address{
+		Network: cidr,
 	}
-	qp := ibclient.NewQueryParams(false, queryAttribute)
-	err := i.Conn.GetObject(net, "", qp, &res)
+}
 
-	if err != nil {
-		log.Error("Failed to get node", err)
-		return *net, err
+type DiscoveryDevice struct {
+	ibclient.IBBase
+	Ref     string `json:"_ref,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Network string `json:"network,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+func (d *DiscoveryDevice) ObjectType() string {
+	return "discovery:device"
+}
+
+func NewDiscoveryDevice(cidr string) *DiscoveryDevice {
+	return &DiscoveryDevice{
+		Network: cidr,
 	}
-	return res[0], nil
 }
 
-func (i InfoBloxApi) Logout() {
-	i.Conn.Logout()
+type ZoneRp struct {
+	ibclient.IBBase
+	Ref                string `json:"_ref,omitempty"`
+	Fqdn               string `json:"fqdn,omitempty"`
+	View               string `json:"view,omitempty"`
+	RpzType            string `json:"rpz_type,omitempty"`
+	RpzLastUpdatedTime int64  `json:"rpz_last_updated_time,omitempty"`
+}
+
+func (z *ZoneRp) ObjectType() string {
+	return "zone_rp"
+}
+
+func NewZoneRp() *ZoneRp {
+	return &ZoneRp{}
+}
+
+type AllRpzRecords struct {
+	ibclient.IBBase
+	Ref  string `json:"_ref,omitempty"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+	View string `json:"view,omitempty"`
+	Zone string `json:"zone,omitempty"`
+}
+
+func (a *AllRpzRecords) ObjectType() string {
+	return "allrpzrecords"
+}
+
+func NewAllRpzRecords(zone string, view string) *AllRpzRecords {
+	return &AllRpzRecords{
+		Zone: zone,
+		View: view,
+	}
+}
+
+type GridThreatProtection struct {
+	ibclient.IBBase
+	Ref                     string `json:"_ref,omitempty"`
+	CurrentRuleset          string `json:"current_ruleset,omitempty"`
+	LastCheckedForUpdate    int64  `json:"last_checked_for_update,omitempty"`
+	LastRuleUpdateTimestamp int64  `json:"last_rule_update_timestamp,omitempty"`
+	LastRuleUpdateVersion   string `json:"last_rule_update_version,omitempty"`
+}
+
+func (g *GridThreatProtection) ObjectType() string {
+	return "grid:threatprotection"
+}
+
+func NewGridThreatProtection() *GridThreatProtection {
+	return &GridThreatProtection{}
+}
+
+type MemberThreatProtection struct {
+	ibclient.IBBase
+	Ref            string `json:"_ref,omitempty"`
+	HostName       string `json:"host_name,omitempty"`
+	Ipv4Address    string `json:"ipv4address,omitempty"`
+	CurrentRuleset string `json:"current_ruleset,omitempty"`
+	EnableService  bool   `json:"enable_service,omitempty"`
+}
+
+func (m *MemberThreatProtection) ObjectType() string {
+	return "member:threatprotection"
+}
+
+func NewMemberThreatProtection() *MemberThreatProtection {
+	return &MemberThreatProtection{}
+}
+
+type MemberLicense struct {
+	ibclient.IBBase
+	Ref          string `json:"_ref,omitempty"`
+	ExpiryDate   int64  `json:"expiry_date,omitempty"`
+	Hwid         string `json:"hwid,omitempty"`
+	Kind         string `json:"kind,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+	LimitContext string `json:"limit_context,omitempty"`
+	Type         string `json:"type,omitempty"`
+}
+
+func (l *MemberLicense) ObjectType() string {
+	return "member:license"
+}
+
+func NewMemberLicense() *MemberLicense {
+	return &MemberLicense{}
+}
+
+type LicensePool struct {
+	ibclient.IBBase
+	Ref          string `json:"_ref,omitempty"`
+	Assigned     int64  `json:"assigned,omitempty"`
+	ExpiryDate   int64  `json:"expiry_date,omitempty"`
+	Installed    int64  `json:"installed,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+	LimitContext string `json:"limit_context,omitempty"`
+	Model        string `json:"model,omitempty"`
+	Type         string `json:"type,omitempty"`
+}
+
+func (l *LicensePool) ObjectType() string {
+	return "grid:license_pool"
+}
+
+func NewLicensePool() *LicensePool {
+	return &LicensePool{}
+}
+
+type CapacityReport struct {
+	ibclient.IBBase
+	Ref          string                `json:"_ref,omitempty"`
+	Name         string                `json:"name,omitempty"`
+	HardwareType string                `json:"hardware_type,omitempty"`
+	MaxCapacity  int64                 `json:"max_capacity,omitempty"`
+	PercentUsed  int64                 `json:"percent_used,omitempty"`
+	TotalObjects int64                 `json:"total_objects,omitempty"`
+	ObjectCounts []CapacityObjectCount `json:"object_counts,omitempty"`
+}
+
+type CapacityObjectCount struct {
+	TypeName string `json:"type_name,omitempty"`
+	Count    int64  `json:"count,omitempty"`
+}
+
+func (c *CapacityReport) ObjectType() string {
+	return "capacityreport"
+}
+
+func NewCapacityReport(nodeName string) *CapacityReport {
+	return &CapacityReport{
+		Name: nodeName,
+	}
+}
+
+// ServiceRestartRequest is the restart request the grid keep for each member and service, needed
+// is REQUIRED while the configuration is changed but the service not restarted
+type ServiceRestartRequest struct {
+	ibclient.IBBase
+	Ref             string `json:"_ref,omitempty"`
+	Member          string `json:"member,omitempty"`
+	Service         string `json:"service,omitempty"`
+	Needed          string `json:"needed,omitempty"`
+	LastUpdatedTime int64  `json:"last_updated_time,omitempty"`
+}
+
+func (r *ServiceRestartRequest) ObjectType() string {
+	return "grid:servicerestart:request"
+}
+
+func NewServiceRestartRequest(nodeName string) *ServiceRestartRequest {
+	return &ServiceRestartRequest{
+		Member: nodeName,
+	}
+}
+
+type X509Certificate struct {
+	ibclient.IBBase
+	Ref            string `json:"_ref,omitempty"`
+	Issuer         string `json:"issuer,omitempty"`
+	Serial         string `json:"serial,omitempty"`
+	Subject        string `json:"subject,omitempty"`
+	ValidNotAfter  int64  `json:"valid_not_after,omitempty"`
+	ValidNotBefore int64  `json:"valid_not_before,omitempty"`
+}
+
+func (c *X509Certificate) ObjectType() string {
+	return "grid:x509certificate"
+}
+
+func NewX509Certificate() *X509Certificate {
+	return &X509Certificate{}
+}
+
+type DtcServer struct {
+	ibclient.IBBase
+	Ref    string             `json:"_ref,omitempty"`
+	Name   string             `json:"name,omitempty"`
+	Host   string             `json:"host,omitempty"`
+	Health ibclient.DtcHealth `json:"health,omitempty"`
+}
+
+func (d *DtcServer) ObjectType() string {
+	return "dtc:server"
+}
+
+func NewDtcServer() *DtcServer {
+	return &DtcServer{}
+}
+
+type DtcPool struct {
+	ibclient.IBBase
+	Ref    string             `json:"_ref,omitempty"`
+	Name   string             `json:"name,omitempty"`
+	Health ibclient.DtcHealth `json:"health,omitempty"`
+}
+
+func (d *DtcPool) ObjectType() string {
+	return "dtc:pool"
+}
+
+func NewDtcPool() *DtcPool {
+	return &DtcPool{}
+}
+
+type DtcLbdn struct {
+	ibclient.IBBase
+	Ref    string             `json:"_ref,omitempty"`
+	Name   string             `json:"name,omitempty"`
+	Health ibclient.DtcHealth `json:"health,omitempty"`
+}
+
+func (d *DtcLbdn) ObjectType() string {
+	return "dtc:lbdn"
+}
+
+func NewDtcLbdn() *DtcLbdn {
+	return &DtcLbdn{}
+}
+
+type InfoBloxApi struct {
+	masters  *masterFailover
+	Config   InfoBloxConfiguration
+	ctx      context.Context
+	limiter  *requestLimiter
+	breaker  *circuitBreaker
+	users    *apiUsers
+	rejected *rejection
+	settings *viper.Viper
+}
+
+// rejection keep the first request of a probe that was rejected by the limiter or the circuit
+// breaker, like ErrQueueFull
+type rejection struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (r *rejection) record(err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *rejection) first() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+// WithContext return a copy of the api where the requests are rejected if they can not be sent
+// before the ctx deadline. The copy keep the first rejected request, see Rejected.
+func (i InfoBloxApi) WithContext(ctx context.Context) InfoBloxApi {
+	i.ctx = ctx
+	i.rejected = &rejection{}
+	return i
+}
+
+// Rejected return the error of the first request rejected by the limiter or the circuit breaker
+// since WithContext, nil if no request was rejected
+func (i InfoBloxApi) Rejected() error {
+	return i.rejected.first()
+}
+
+// Context return the ctx of the probe, or the background ctx if the api is not used by a probe
+func (i InfoBloxApi) Context() context.Context {
+	if i.ctx == nil {
+		return context.Background()
+	}
+	return i.ctx
+}
+
+// ActiveMaster return the address of the grid master that answer the requests
+func (i InfoBloxApi) ActiveMaster() string {
+	return i.masters.activeAddress()
+}
+
+// MasterApis return an api for each address of the grid master, in the configured order, without
+// failover, limiter and circuit breaker, so every address can be checked
+func (i InfoBloxApi) MasterApis() []InfoBloxApi {
+	var apis []InfoBloxApi
+	for index, conn := range i.masters.conns {
+		api := i
+		api.masters = &masterFailover{
+			master:    i.masters.master,
+			addresses: i.masters.addresses[index : index+1],
+			conns:     []*ibclient.Connector{conn},
+			used:      make([]atomic.Bool, 1),
+		}
+		api.limiter = nil
+		api.breaker = nil
+		apis = append(apis, api)
+	}
+	return apis
+}
+
+// CircuitOpen report if requests to the grid master fail fast since it is not available
+func (i InfoBloxApi) CircuitOpen() bool {
+	return i.breaker != nil && i.breaker.isOpen()
+}
+
+// getObject get the object from the WAPI when the limiter and the circuit breaker allow it
+func (i InfoBloxApi) getObject(obj ibclient.IBObject, ref string, queryParams *ibclient.QueryParams,
+	res interface{}) error {
+	return i.send(func() error {
+		return i.masters.getObject(i.Context(), obj, ref, queryParams, res)
+	})
+}
+
+// send the request to the grid master when the limiter and the circuit breaker allow it
+func (i InfoBloxApi) send(request func() error) error {
+	if i.limiter != nil {
+		release, err := i.limiter.acquire(i.Context())
+		if err != nil {
+			i.rejected.record(err)
+			return err
+		}
+		defer release()
+	}
+	if i.breaker == nil {
+		return request()
+	}
+
+	err := i.breaker.allow()
+	if err != nil {
+		i.rejected.record(err)
+		return err
+	}
+	err = request()
+	i.breaker.record(err)
+	return err
+}
+
+// NewInfobloxApi create the api from the configuration, the configuration can be a reloaded one
+// that is not yet running
+func NewInfobloxApi(v *viper.Viper) (InfoBloxApi, error) {
+	config := NewInfoBloxConfiguration(v)
+	if len(config.Masters) == 0 {
+		return InfoBloxApi{}, fmt.Errorf("no infoblox master configured")
+	}
+
+	password, err := config.Password.Secret()
+	if err != nil {
+		return InfoBloxApi{}, fmt.Errorf("failed to get password: %w", err)
+	}
+	authConfig := ibclient.AuthConfig{
+		Username:   config.Username,
+		Password:   password,
+		ClientCert: nil,
+		ClientKey:  nil,
+	}
+	if config.ClientCertFile != "" || config.ClientKeyFile != "" {
+		cert, key, err := readClientCertificate(config.ClientCertFile, config.ClientKeyFile)
+		if err != nil {
+			return InfoBloxApi{}, fmt.Errorf("failed to read client certificate: %w", err)
+		}
+		authConfig.ClientCert = cert
+		authConfig.ClientKey = key
+	}
+
+	// The ibclient use the CA bundle if the path is passed instead of true
+	sslVerify := strconv.FormatBool(config.SSLVerify)
+	if config.SSLVerify && config.CAFile != "" {
+		err := CheckCAFile(config.CAFile)
+		if err != nil {
+			return InfoBloxApi{}, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		sslVerify = config.CAFile
+	}
+	transportConfig := ibclient.NewTransportConfig(sslVerify, config.HTTPRequestTimeout,
+		config.HTTPPoolConnections)
+
+	var conns []*ibclient.Connector
+	for _, address := range config.Masters {
+		hostConfig := ibclient.HostConfig{
+			Host:    address,
+			Version: config.Version,
+			Port:    config.port(),
+		}
+		requestBuilder := &secretRequestBuilder{
+			HttpRequestBuilder: &ibclient.WapiRequestBuilder{},
+			username:           config.Username,
+			password:           config.Password,
+		}
+		requestor := &ibclient.WapiHttpRequestor{}
+		conn, err := ibclient.NewConnector(hostConfig, authConfig, transportConfig, requestBuilder, requestor)
+		if err != nil {
+			return InfoBloxApi{}, fmt.Errorf("failed to connect to %s: %w", address, err)
+		}
+		conns = append(conns, conn)
+	}
+
+	limiter := newRequestLimiter(config.Master, config.MaxConcurrentRequests, config.MaxQueuedRequests,
+		config.RequestsPerSecond)
+
+	breaker := newCircuitBreaker(config.Master, config.BreakerMaxFailures,
+		time.Duration(config.BreakerOpenSeconds)*time.Second)
+
+	return InfoBloxApi{masters: newMasterFailover(config.Masters, conns), Config: config, limiter: limiter,
+		breaker: breaker, users: newApiUsers(), settings: v}, nil
+}
+
+// Settings return the configuration the api was created from
+func (i InfoBloxApi) Settings() *viper.Viper {
+	if i.settings == nil {
+		return viper.GetViper()
+	}
+	return i.settings
+}
+
+// secretRequestBuilder set the basic auth password from the secret provider on every request, so a
+// rotated password is used without creating a new connector
+type secretRequestBuilder struct {
+	ibclient.HttpRequestBuilder
+	username string
+	password secrets.Provider
+}
+
+func (b *secretRequestBuilder) BuildRequest(t ibclient.RequestType, obj ibclient.IBObject, ref string,
+	queryParams *ibclient.QueryParams) (*http.Request, error) {
+	req, err := b.HttpRequestBuilder.BuildRequest(t, obj, ref, queryParams)
+	if err != nil || b.username == "" {
+		return req, err
+	}
+	password, err := b.password.Secret()
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(b.username, password)
+	return req, nil
+}
+
+// readClientCertificate read the PEM encoded client certificate and key used to authenticate to the
+// WAPI. The pair is validated here since the ibclient exit the process on an invalid pair.
+func readClientCertificate(certFile string, keyFile string) ([]byte, []byte, error) {
+	cert, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// CheckCAFile verify that the CA file can be read and contain PEM certificates, the ibclient
+// silently disable the certificate verification otherwise
+func CheckCAFile(caFile string) error {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return err
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no PEM certificates in %s", caFile)
+	}
+	return nil
+}
+
+func (i InfoBloxApi) GetDhcpUtilization(network string) (Range, error) {
+	var res []Range
+	net := NewRange(network, "", nil)
+
+	queryAttribute := map[string]string{
+		"network":        network,
+		"_return_fields": "extattrs,network,dhcp_utilization,comment",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(net, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get network", err)
+		return *net, err
+	}
+
+	return res[0], nil
+}
+
+// GetRanges return the DHCP ranges in the network, paged like GetFixedAddresses
+func (i InfoBloxApi) GetRanges(network string) ([]Range, error) {
+	net := NewRange(network, "", nil)
+
+	queryAttribute := map[string]string{
+		"network":        network,
+		"_return_fields": "network,start_addr,end_addr,server_association_type,member,failover_association",
+	}
+	res, err := getPaged[Range](i, net, queryAttribute)
+
+	if err != nil && !isNotFound(err) {
+		log.Error("Failed to get ranges", err)
+		return res, err
+	}
+	return res, nil
+}
+
+// GetFailoverRanges return the ranges served by the failover association
+func (i InfoBloxApi) GetFailoverRanges(failover string) ([]Range, error) {
+	net := NewRange("", "", nil)
+
+	queryAttribute := map[string]string{
+		"failover_association": failover,
+		"_return_fields":       "network,start_addr,end_addr,server_association_type,member,failover_association",
+	}
+	res, err := getPaged[Range](i, net, queryAttribute)
+
+	if err != nil && !isNotFound(err) {
+		log.Error("Failed to get failover ranges", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetDhcpFailover(name string) (DhcpFailover, error) {
+	var res []DhcpFailover
+	failover := NewDhcpFailover(name)
+
+	queryAttribute := map[string]string{
+		"name":           name,
+		"_return_fields": "name,primary,primary_server_type,secondary,secondary_server_type",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(failover, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get dhcp failover", err)
+		return *failover, err
+	}
+	return res[0], nil
+}
+
+func (i InfoBloxApi) GetMemberDhcpProperties(nodeName string) (MemberDhcpProperties, error) {
+	var res []MemberDhcpProperties
+	props := NewMemberDhcpProperties(nodeName)
+
+	queryAttribute := map[string]string{
+		"host_name": nodeName,
+		"_return_fields": "host_name,options,enable_dhcp,authority,enable_ddns,ping_count,ping_timeout," +
+			"lease_scavenge_time,recycle_leases",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(props, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get member dhcp properties", err)
+		return *props, err
+	}
+	return res[0], nil
+}
+
+// GetFixedAddresses return the fixed addresses and reservations in the network, paged since a large
+// network can have more than the WAPI max results
+func (i InfoBloxApi) GetFixedAddresses(network string) ([]FixedAddress, error) {
+	fixed := NewFixedAddress(network)
+
+	queryAttribute := map[string]string{
+		"network":        network,
+		"_return_fields": "ipv4addr,mac,match_client,disable,network",
+	}
+	res, err := getPaged[FixedAddress](i, fixed, queryAttribute)
+
+	if err != nil && !isNotFound(err) {
+		log.Error("Failed to get fixed addresses", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetMember(nodeName string) (Member, error) {
+	var res []Member
+	net := NewMember(nodeName)
+
+	queryAttribute := map[string]string{
+		"host_name":      nodeName,
+		"_return_fields": "extattrs,host_name,node_info,service_status",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(net, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get node", err)
+		return *net, err
+	}
+	return res[0], nil
+}
+
+func (i InfoBloxApi) GetMemberNtp(nodeName string) (Member, error) {
+	var res []Member
+	mem := NewMember(nodeName)
+
+	queryAttribute := map[string]string{
+		"host_name":      nodeName,
+		"_return_fields": "host_name,ntp_setting",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(mem, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get member ntp", err)
+		return *mem, err
+	}
+	return res[0], nil
+}
+
+func (i InfoBloxApi) GetGridNtp() (Grid, error) {
+	var res []Grid
+	grid := NewGrid()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "name,ntp_setting",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(grid, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get grid ntp", err)
+		return *grid, err
+	}
+	return res[0], nil
+}
+
+func (i InfoBloxApi) GetGrid() (Grid, error) {
+	var res []Grid
+	grid := NewGrid()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "name",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(grid, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get grid", err)
+		return *grid, err
+	}
+	return res[0], nil
+}
+
+// GetSchema return the WAPI schema. The request fail if the credentials are not valid or the
+// configured WAPI version is not supported by the grid master.
+func (i InfoBloxApi) GetSchema() (WapiSchema, error) {
+	var res WapiSchema
+	schema := NewWapiSchema()
+
+	queryAttribute := map[string]string{
+		"_schema": "1",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(schema, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get schema", err)
+		return res, err
+	}
+	return res, nil
+}
+
+// GetUpgradeStatus return the upgrade status for the statusType, GRID for the grid and VNODE for
+// each member
+func (i InfoBloxApi) GetUpgradeStatus(statusType string) ([]UpgradeStatus, error) {
+	var res []UpgradeStatus
+	status := NewUpgradeStatus(statusType)
+
+	queryAttribute := map[string]string{
+		"type":           statusType,
+		"_return_fields": "type,member,current_version,status_value,upgrade_group,upgrade_state",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(status, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get upgrade status", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetMemberDns(nodeName string) (MemberDns, error) {
+	var res []MemberDns
+	dns := NewMemberDns(nodeName)
+
+	queryAttribute := map[string]string{
+		"host_name":      nodeName,
+		"_return_fields": "host_name,forwarders,forward_only,use_forwarders",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(dns, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get member dns", err)
+		return *dns, err
+	}
+	return res[0], nil
+}
+
+func (i InfoBloxApi) GetGridDns() (GridDns, error) {
+	var res []GridDns
+	dns := NewGridDns()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "forwarders,forward_only",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(dns, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get grid dns", err)
+		return *dns, err
+	}
+	return res[0], nil
+}
+
+func (i InfoBloxApi) GetMembers() ([]Member, error) {
+	var res []Member
+	mem := NewMember("")
+
+	queryAttribute := map[string]string{
+		"_return_fields": "host_name,node_info,platform,vip_setting,master_candidate",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(mem, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get members", err)
+		return res, err
+	}
+	return res, nil
+}
+
+// GetMemberLicenses return the licenses of all members, paged since a grid has several licenses per node
+func (i InfoBloxApi) GetMemberLicenses() ([]MemberLicense, error) {
+	lic := NewMemberLicense()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "expiry_date,hwid,kind,limit,limit_context,type",
+	}
+	res, err := getPaged[MemberLicense](i, lic, queryAttribute)
+
+	if err != nil && !isNotFound(err) {
+		log.Error("Failed to get member licenses", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetLicensePools() ([]LicensePool, error) {
+	var res []LicensePool
+	pool := NewLicensePool()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "assigned,expiry_date,installed,limit,limit_context,model,type",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(pool, "", qp, &res)
+
+	if err != nil && !isNotFound(err) {
+		log.Error("Failed to get license pools", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetCapacityReport(nodeName string) (CapacityReport, error) {
+	var res []CapacityReport
+	report := NewCapacityReport(nodeName)
+
+	queryAttribute := map[string]string{
+		"name":           nodeName,
+		"_return_fields": "name,hardware_type,max_capacity,percent_used,total_objects,object_counts",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(report, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get capacity report", err)
+		return *report, err
+	}
+	return res[0], nil
+}
+
+// GetServiceRestartRequests return the restart requests of the member, one for each service
+func (i InfoBloxApi) GetServiceRestartRequests(nodeName string) ([]ServiceRestartRequest, error) {
+	var res []ServiceRestartRequest
+	request := NewServiceRestartRequest(nodeName)
+
+	queryAttribute := map[string]string{
+		"member":         nodeName,
+		"_return_fields": "member,service,needed,last_updated_time",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(request, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get service restart requests", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetX509Certificates() ([]X509Certificate, error) {
+	var res []X509Certificate
+	cert := NewX509Certificate()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "issuer,serial,subject,valid_not_after,valid_not_before",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(cert, "", qp, &res)
+
+	if err != nil && !isNotFound(err) {
+		log.Error("Failed to get x509 certificates", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetDtcServers() ([]DtcServer, error) {
+	var res []DtcServer
+	server := NewDtcServer()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "name,host,health",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(server, "", qp, &res)
+
+	if err != nil && !isNotFound(err) {
+		log.Error("Failed to get dtc servers", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetDtcPools() ([]DtcPool, error) {
+	var res []DtcPool
+	pool := NewDtcPool()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "name,health",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(pool, "", qp, &res)
+
+	if err != nil && !isNotFound(err) {
+		log.Error("Failed to get dtc pools", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetDtcLbdns() ([]DtcLbdn, error) {
+	var res []DtcLbdn
+	lbdn := NewDtcLbdn()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "name,health",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(lbdn, "", qp, &res)
+
+	if err != nil && !isNotFound(err) {
+		log.Error("Failed to get dtc lbdns", err)
+		return res, err
+	}
+	return res, nil
+}
+
+// port return the WAPI port, empty for the https default
+func (c InfoBloxConfiguration) port() string {
+	if c.Port == 0 {
+		return ""
+	}
+	return strconv.FormatInt(c.Port, 10)
+}
+
+// GetMasterCertificate return the certificate the active grid master present in the TLS handshake.
+// The ibclient transport do not expose the peer certificates so a separate handshake is done.
+// The certificate is not verified since an expired or untrusted certificate should still be
+// reported.
+func (i InfoBloxApi) GetMasterCertificate() (*x509.Certificate, error) {
+	port := i.Config.port()
+	if port == "" {
+		port = "443"
+	}
+
+	var cert *x509.Certificate
+	master := i.ActiveMaster()
+	err := i.send(func() error {
+		dialer := &tls.Dialer{
+			NetDialer: &net.Dialer{Timeout: time.Duration(i.Config.HTTPRequestTimeout) * time.Second},
+			Config:    &tls.Config{InsecureSkipVerify: true, ServerName: master},
+		}
+		conn, err := dialer.DialContext(i.Context(), "tcp", net.JoinHostPort(master, port))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+		if len(certs) == 0 {
+			return fmt.Errorf("no certificate presented by %s", master)
+		}
+		cert = certs[0]
+		return nil
+	})
+	if err != nil {
+		log.Error("Failed to connect to grid master", err)
+		return nil, err
+	}
+	return cert, nil
+}
+
+func (i InfoBloxApi) GetAllRecords(zone string) ([]AllRecords, error) {
+	records := NewAllRecords(zone)
+
+	queryAttribute := map[string]string{
+		"zone":           zone,
+		"_return_fields": "name,type,view,zone",
+	}
+	res, err := getPaged[AllRecords](i, records, queryAttribute)
+
+	if err != nil {
+		log.Error("Failed to get records", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetDnsRecords(recordType string, zone string) ([]DnsRecord, error) {
+	records := NewDnsRecord(recordType, zone)
+
+	queryAttribute := map[string]string{
+		"zone":           zone,
+		"_return_fields": "name,view,zone,last_queried",
+	}
+	res, err := getPaged[DnsRecord](i, records, queryAttribute)
+
+	if err != nil {
+		log.Error("Failed to get records", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetScheduledTasks() ([]ScheduledTask, error) {
+	task := NewScheduledTask()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "task_id,approval_status,execution_status,submit_time,submitter",
+	}
+	res, err := getPaged[ScheduledTask](i, task, queryAttribute)
+
+	if err != nil {
+		log.Error("Failed to get scheduled tasks", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetAdminUsers() ([]AdminUser, error) {
+	user := NewAdminUser()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "name,admin_groups",
+	}
+	res, err := getPaged[AdminUser](i, user, queryAttribute)
+
+	if err != nil {
+		log.Error("Failed to get admin users", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetUsedAddresses(network string) ([]Ipv4Address, error) {
+	address := NewIpv4Address(network)
+
+	queryAttribute := map[string]string{
+		"network":        network,
+		"status":         "USED",
+		"_return_fields": "ip_address,network,status,types,discovered_data",
+	}
+	res, err := getPaged[Ipv4Address](i, address, queryAttribute)
+
+	if err != nil {
+		log.Error("Failed to get addresses", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetDiscoveryDevices(network string) ([]DiscoveryDevice, error) {
+	device := NewDiscoveryDevice(network)
+
+	queryAttribute := map[string]string{
+		"network":        network,
+		"_return_fields": "name,network,type",
+	}
+	res, err := getPaged[DiscoveryDevice](i, device, queryAttribute)
+
+	if err != nil {
+		log.Error("Failed to get discovery devices", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetRpzZones() ([]ZoneRp, error) {
+	zone := NewZoneRp()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "fqdn,view,rpz_type,rpz_last_updated_time",
+	}
+	res, err := getPaged[ZoneRp](i, zone, queryAttribute)
+
+	if err != nil {
+		log.Error("Failed to get rpz zones", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetRpzRecords(zone string, view string) ([]AllRpzRecords, error) {
+	records := NewAllRpzRecords(zone, view)
+
+	queryAttribute := map[string]string{
+		"zone":           zone,
+		"view":           view,
+		"_return_fields": "type",
+	}
+	res, err := getPaged[AllRpzRecords](i, records, queryAttribute)
+
+	if err != nil {
+		log.Error("Failed to get rpz records", err)
+		return res, err
+	}
+	return res, nil
+}
+
+func (i InfoBloxApi) GetGridThreatProtection() (GridThreatProtection, error) {
+	var res []GridThreatProtection
+	tp := NewGridThreatProtection()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "current_ruleset,last_checked_for_update,last_rule_update_timestamp,last_rule_update_version",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(tp, "", qp, &res)
+
+	if err != nil {
+		log.Error("Failed to get grid threat protection", err)
+		return *tp, err
+	}
+	return res[0], nil
+}
+
+func (i InfoBloxApi) GetMemberThreatProtection() ([]MemberThreatProtection, error) {
+	var res []MemberThreatProtection
+	tp := NewMemberThreatProtection()
+
+	queryAttribute := map[string]string{
+		"_return_fields": "host_name,ipv4address,current_ruleset,enable_service",
+	}
+	qp := ibclient.NewQueryParams(false, queryAttribute)
+	err := i.getObject(tp, "", qp, &res)
+
+	if err != nil && !isNotFound(err) {
+		log.Error("Failed to get member threat protection", err)
+		return res, err
+	}
+	return res, nil
+}
+
+// getPaged fetch all objects using the WAPI paging, with page_size objects in each request, so that
+// large result sets do not time out or hit the WAPI max results limit
+func getPaged[T any](i InfoBloxApi, obj ibclient.IBObject, queryAttribute map[string]string) ([]T, error) {
+	var all []T
+
+	pageSize := i.Config.PageSize
+	if pageSize <= 0 {
+		pageSize = 1000
+	}
+	queryAttribute["_paging"] = "1"
+	queryAttribute["_return_as_object"] = "1"
+	queryAttribute["_max_results"] = strconv.Itoa(pageSize)
+
+	for {
+		var page pagedResult[T]
+		qp := ibclient.NewQueryParams(false, queryAttribute)
+		err := i.getObject(obj, "", qp, &page)
+		if err != nil {
+			return all, err
+		}
+		all = append(all, page.Result...)
+
+		if page.NextPageId == "" {
+			return all, nil
+		}
+		// Following pages are only requested by the page id
+		queryAttribute = map[string]string{
+			"_page_id": page.NextPageId,
+		}
+	}
+}
+
+// isNotFound return true if the WAPI returned an empty result, which is a valid
+// answer for objects that are listed rather than looked up
+func isNotFound(err error) bool {
+	_, ok := err.(*ibclient.NotFoundError)
+	return ok
+}
+
+func (i InfoBloxApi) Logout() {
+	i.masters.logout()
+}
+
+// Retire stop new users from acquiring the api, the returned channel is closed when the last user
+// has released it
+func (i InfoBloxApi) Retire() <-chan struct{} {
+	return i.users.retire()
+}
+
+// DeleteMetrics delete the limiter, circuit breaker and grid master series of the api master, so a
+// master removed by a reload is no longer exported. Must only be called when the api is retired and
+// released.
+func (i InfoBloxApi) DeleteMetrics() {
+	labels := prometheus.Labels{"master": i.Config.Master}
+	wapiQueueLength.DeletePartialMatch(labels)
+	wapiInFlight.DeletePartialMatch(labels)
+	wapiQueueWait.DeletePartialMatch(labels)
+	wapiRejected.DeletePartialMatch(labels)
+	circuitBreakerState.DeletePartialMatch(labels)
+	gridMasterInfo.DeletePartialMatch(labels)
 }
@@ -0,0 +1,134 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+
+	ibclient "github.com/infobloxopen/infoblox-go-client/v2"
+)
+
+// newTestConnector create a WAPI connector to the test server
+func newTestConnector(t *testing.T, serverURL string) *ibclient.Connector {
+	t.Helper()
+
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := ibclient.NewConnector(
+		ibclient.HostConfig{Host: host, Port: port, Version: "2.10.5"},
+		ibclient.AuthConfig{Username: "admin", Password: "infoblox"},
+		ibclient.NewTransportConfig("false", 2, 2),
+		&ibclient.WapiRequestBuilder{}, &ibclient.WapiHttpRequestor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// newTestApi create an api with a single grid master address served by the handler
+func newTestApi(t *testing.T, handler http.Handler, pageSize int) InfoBloxApi {
+	t.Helper()
+
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	return InfoBloxApi{
+		masters: newMasterFailover([]string{"test"}, []*ibclient.Connector{newTestConnector(t, server.URL)}),
+		Config:  InfoBloxConfiguration{Master: "test", PageSize: pageSize},
+	}
+}
+
+func TestGetPaged(t *testing.T) {
+	records := []AllRecords{
+		{Name: "a", Type: "record:a"}, {Name: "b", Type: "record:a"}, {Name: "c", Type: "record:a"},
+		{Name: "d", Type: "record:a"}, {Name: "e", Type: "record:a"},
+	}
+	pages := map[string]pagedResult[AllRecords]{
+		"":      {Result: records[0:2], NextPageId: "page2"},
+		"page2": {Result: records[2:4], NextPageId: "page3"},
+		"page3": {Result: records[4:]},
+	}
+
+	var mu sync.Mutex
+	var queries []url.Values
+	api := newTestApi(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Query())
+		mu.Unlock()
+
+		page, ok := pages[r.URL.Query().Get("_page_id")]
+		if !ok {
+			http.Error(w, "unknown page", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}), 2)
+
+	got, err := getPaged[AllRecords](api, NewAllRecords("foo.com"), map[string]string{"zone": "foo.com"})
+	if err != nil {
+		t.Fatalf("getPaged: %v", err)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("got %v, want %v", got, records)
+	}
+
+	if len(queries) != 3 {
+		t.Fatalf("got %d requests, want 3", len(queries))
+	}
+	first := queries[0]
+	for key, want := range map[string]string{"zone": "foo.com", "_paging": "1", "_return_as_object": "1",
+		"_max_results": "2"} {
+		if first.Get(key) != want {
+			t.Errorf("first request %s=%q, want %q", key, first.Get(key), want)
+		}
+	}
+	// Following pages are only requested by the page id
+	for _, query := range queries[1:] {
+		if query.Get("zone") != "" || query.Get("_paging") != "" {
+			t.Errorf("page request with query %v, want only _page_id", query)
+		}
+	}
+}
+
+func TestGetPagedError(t *testing.T) {
+	api := newTestApi(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("_page_id") == "" {
+			_ = json.NewEncoder(w).Encode(pagedResult[AllRecords]{
+				Result: []AllRecords{{Name: "a"}}, NextPageId: "page2"})
+			return
+		}
+		http.Error(w, "page expired", http.StatusBadRequest)
+	}), 1)
+
+	got, err := getPaged[AllRecords](api, NewAllRecords("foo.com"), map[string]string{"zone": "foo.com"})
+	if err == nil {
+		t.Fatal("getPaged returned no error for a failed page")
+	}
+	if len(got) != 1 {
+		t.Errorf("got %d records before the failed page, want 1", len(got))
+	}
+}
@@ -0,0 +1,159 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var prefixLicense = fmt.Sprintf("%s_%s", prefix, "license")
+var licenseLabels = []string{"member", "hwid", "type", "kind"}
+var licenseLimitLabels = []string{"member", "hwid", "type", "kind", "limit_context"}
+var licensePoolLabels = []string{"type", "model"}
+var licensePoolLimitLabels = []string{"type", "model", "limit_context"}
+
+var (
+	licenseExpiry = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixLicense, "expiry_timestamp_seconds"),
+		"Member license expiry as unix timestamp in seconds",
+		licenseLabels, nil,
+	)
+	licenseLimit = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixLicense, "limit"),
+		"Member license capacity limit",
+		licenseLimitLabels, nil,
+	)
+	licensePoolExpiry = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixLicense, "pool_expiry_timestamp_seconds"),
+		"Grid license pool expiry as unix timestamp in seconds",
+		licensePoolLabels, nil,
+	)
+	licensePoolInstalled = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixLicense, "pool_installed"),
+		"Number of dynamic licenses installed in the grid license pool",
+		licensePoolLabels, nil,
+	)
+	licensePoolAssigned = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixLicense, "pool_assigned"),
+		"Number of dynamic licenses assigned from the grid license pool",
+		licensePoolLabels, nil,
+	)
+	licensePoolLimit = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixLicense, "pool_limit"),
+		"Grid license pool capacity limit",
+		licensePoolLimitLabels, nil,
+	)
+)
+
+func probeLicenses(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	members, err := api.GetMembers()
+	if err != nil {
+		return m, false
+	}
+
+	licenses, err := api.GetMemberLicenses()
+	if err != nil {
+		return m, false
+	}
+
+	pools, err := api.GetLicensePools()
+	if err != nil {
+		return m, false
+	}
+
+	m = metricsLicenses(members, licenses, m)
+	m = metricsLicensePools(pools, m)
+
+	return m, true
+}
+
+func metricsLicenses(members []Member, licenses []MemberLicense, m []prometheus.Metric) []prometheus.Metric {
+
+	// Licenses are installed per physical node, identified by hwid
+	hwidToMember := make(map[string]string)
+	for _, mem := range members {
+		for _, node := range mem.Nodeinfo {
+			hwidToMember[node.Hwid] = mem.HostName
+		}
+	}
+
+	// A node can have multiple licenses of the same type, e.g. a temporary and a
+	// permanent, so only the one that expires last is reported
+	expiry := make(map[string]MemberLicense)
+	limits := make(map[string]MemberLicense)
+	for _, lic := range licenses {
+		key := lic.Hwid + "/" + lic.Type + "/" + lic.Kind
+		if lic.ExpiryDate > 0 {
+			if prev, ok := expiry[key]; !ok || lic.ExpiryDate > prev.ExpiryDate {
+				expiry[key] = lic
+			}
+		}
+		if _, ok := limits[key]; !ok && lic.Limit != "" {
+			limits[key] = lic
+		}
+	}
+
+	for _, lic := range expiry {
+		m = append(m, prometheus.MustNewConstMetric(licenseExpiry, prometheus.GaugeValue, float64(lic.ExpiryDate),
+			hwidToMember[lic.Hwid], lic.Hwid, lic.Type, lic.Kind))
+	}
+
+	for _, lic := range limits {
+		limit, err := strconv.ParseFloat(lic.Limit, 64)
+		if err != nil {
+			continue
+		}
+		m = append(m, prometheus.MustNewConstMetric(licenseLimit, prometheus.GaugeValue, limit,
+			hwidToMember[lic.Hwid], lic.Hwid, lic.Type, lic.Kind, lic.LimitContext))
+	}
+
+	return m
+}
+
+func metricsLicensePools(pools []LicensePool, m []prometheus.Metric) []prometheus.Metric {
+
+	dup := make(map[string]string)
+	for _, pool := range pools {
+		key := pool.Type + "/" + pool.Model
+		_, ok := dup[key]
+		if ok {
+			continue
+		} else {
+			dup[key] = key
+		}
+
+		if pool.ExpiryDate > 0 {
+			m = append(m, prometheus.MustNewConstMetric(licensePoolExpiry, prometheus.GaugeValue, float64(pool.ExpiryDate),
+				pool.Type, pool.Model))
+		}
+		m = append(m, prometheus.MustNewConstMetric(licensePoolInstalled, prometheus.GaugeValue, float64(pool.Installed),
+			pool.Type, pool.Model))
+		m = append(m, prometheus.MustNewConstMetric(licensePoolAssigned, prometheus.GaugeValue, float64(pool.Assigned),
+			pool.Type, pool.Model))
+
+		limit, err := strconv.ParseFloat(pool.Limit, 64)
+		if err == nil {
+			m = append(m, prometheus.MustNewConstMetric(licensePoolLimit, prometheus.GaugeValue, limit,
+				pool.Type, pool.Model, pool.LimitContext))
+		}
+	}
+
+	return m
+}
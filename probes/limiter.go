@@ -0,0 +1,163 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const exporterPrefix = "infoblox_exporter"
+
+var (
+	ErrQueueFull      = errors.New("wapi request queue is full")
+	ErrDeadlineMissed = errors.New("wapi request would miss the probe deadline")
+)
+
+var (
+	wapiQueueLength = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: exporterPrefix + "_wapi_queue_length",
+		Help: "Number of WAPI requests waiting for a free slot",
+	},
+		[]string{"master"},
+	)
+	wapiInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: exporterPrefix + "_wapi_requests_in_flight",
+		Help: "Number of WAPI requests in flight",
+	},
+		[]string{"master"},
+	)
+	wapiQueueWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    exporterPrefix + "_wapi_queue_wait_seconds",
+		Help:    "Histogram of the time (in seconds) WAPI requests waited in the queue",
+		Buckets: []float64{0.001, 0.010, 0.100, 0.500, 1.000, 2.000, 5.000, 10.000},
+	},
+		[]string{"master"},
+	)
+	wapiRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: exporterPrefix + "_wapi_requests_rejected_total",
+		Help: "Number of WAPI requests rejected by the limiter",
+	},
+		[]string{"master", "reason"},
+	)
+)
+
+// requestLimiter limit the number of concurrent requests and optionally the requests per second to
+// a grid master. Requests wait in a bounded queue for a free slot.
+type requestLimiter struct {
+	master   string
+	slots    chan struct{}
+	maxQueue int64
+	queued   atomic.Int64
+
+	// interval between requests if a requests per second limit is set
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+// newRequestLimiter create a limiter, maxConcurrent 0 is no limit of concurrent requests and
+// requestsPerSecond 0 is no rate limit
+func newRequestLimiter(master string, maxConcurrent int, maxQueue int, requestsPerSecond int) *requestLimiter {
+	limiter := &requestLimiter{
+		master:   master,
+		maxQueue: int64(maxQueue),
+	}
+	if maxConcurrent > 0 {
+		limiter.slots = make(chan struct{}, maxConcurrent)
+	}
+	if requestsPerSecond > 0 {
+		limiter.interval = time.Second / time.Duration(requestsPerSecond)
+	}
+	return limiter
+}
+
+// acquire wait for the rate limit and then a free slot and return the function to release it. The request is rejected if
+// the queue is full or if the ctx deadline will pass before it can be sent.
+func (l *requestLimiter) acquire(ctx context.Context) (func(), error) {
+	start := time.Now()
+
+	queued := l.queued.Add(1)
+	wapiQueueLength.WithLabelValues(l.master).Inc()
+	defer func() {
+		l.queued.Add(-1)
+		wapiQueueLength.WithLabelValues(l.master).Dec()
+	}()
+
+	if l.maxQueue > 0 && queued > l.maxQueue {
+		return nil, l.reject("queue_full", ErrQueueFull)
+	}
+
+	// The rate is waited for before a slot is taken, so a request waiting for its send time does not
+	// hold a slot or count as in flight
+	if l.interval > 0 {
+		wait, ok := l.reserve(ctx)
+		if !ok {
+			return nil, l.reject("deadline", ErrDeadlineMissed)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, l.reject("deadline", ErrDeadlineMissed)
+		}
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, l.reject("deadline", ErrDeadlineMissed)
+		}
+	}
+	wapiInFlight.WithLabelValues(l.master).Inc()
+
+	wapiQueueWait.WithLabelValues(l.master).Observe(time.Since(start).Seconds())
+	return func() {
+		wapiInFlight.WithLabelValues(l.master).Dec()
+		if l.slots != nil {
+			<-l.slots
+		}
+	}, nil
+}
+
+// reserve the next free send time for the rate limit and return the time to wait. Nothing is
+// reserved if the wait would pass the ctx deadline.
+func (l *requestLimiter) reserve(ctx context.Context) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	sendAt := l.next
+	if sendAt.Before(now) {
+		sendAt = now
+	}
+	if deadline, ok := ctx.Deadline(); ok && sendAt.After(deadline) {
+		return 0, false
+	}
+	l.next = sendAt.Add(l.interval)
+	return sendAt.Sub(now), true
+}
+
+func (l *requestLimiter) reject(reason string, err error) error {
+	wapiRejected.WithLabelValues(l.master, reason).Inc()
+	return err
+}
@@ -0,0 +1,124 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterQueueFull(t *testing.T) {
+	l := newRequestLimiter("limiter-queue-full", 1, 1, 0)
+
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("first request rejected: %v", err)
+	}
+
+	// The second request wait in the queue for the slot
+	queued := make(chan error)
+	go func() {
+		release, err := l.acquire(context.Background())
+		if err == nil {
+			release()
+		}
+		queued <- err
+	}()
+	for l.queued.Load() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err = l.acquire(context.Background())
+	if !errors.Is(err, ErrQueueFull) {
+		t.Errorf("third request returned %v, want ErrQueueFull", err)
+	}
+
+	release()
+	if err := <-queued; err != nil {
+		t.Errorf("queued request rejected: %v", err)
+	}
+}
+
+func TestLimiterDeadlineWaitingForSlot(t *testing.T) {
+	l := newRequestLimiter("limiter-slot-deadline", 1, 0, 0)
+
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("first request rejected: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = l.acquire(ctx)
+	if !errors.Is(err, ErrDeadlineMissed) {
+		t.Errorf("request returned %v, want ErrDeadlineMissed", err)
+	}
+}
+
+func TestLimiterDeadlineRateLimit(t *testing.T) {
+	// One request per second, the second request can not be sent before the deadline
+	l := newRequestLimiter("limiter-rate-deadline", 0, 0, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	release, err := l.acquire(ctx)
+	if err != nil {
+		t.Fatalf("first request rejected: %v", err)
+	}
+	release()
+
+	start := time.Now()
+	_, err = l.acquire(ctx)
+	if !errors.Is(err, ErrDeadlineMissed) {
+		t.Errorf("request returned %v, want ErrDeadlineMissed", err)
+	}
+	if time.Since(start) > 50*time.Millisecond {
+		t.Errorf("request rejected after %s, want it rejected without waiting", time.Since(start))
+	}
+}
+
+func TestLimiterRateWaitDoNotHoldSlot(t *testing.T) {
+	// The second request wait 200 milliseconds for its send time
+	l := newRequestLimiter("limiter-rate-slot", 1, 0, 5)
+
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("first request rejected: %v", err)
+	}
+	release()
+
+	acquired := make(chan error)
+	go func() {
+		release, err := l.acquire(context.Background())
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+	for l.queued.Load() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if len(l.slots) != 0 {
+		t.Errorf("request waiting for the rate limit hold %d slots, want 0", len(l.slots))
+	}
+	if err := <-acquired; err != nil {
+		t.Errorf("rate limited request rejected: %v", err)
+	}
+}
@@ -42,11 +42,11 @@ var (
 	)
 )
 
-func probeMember(target string) ([]prometheus.Metric, bool) {
+func probeMember(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
 
 	var m []prometheus.Metric
 
-	member, err := infobloxApi.GetMember(target)
+	member, err := api.GetMember(target)
 	if err != nil {
 		return m, false
 	}
@@ -0,0 +1,147 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var memberNtpServerLabels = []string{"address", "preferred", "source"}
+var memberForwarderLabels = []string{"address", "source"}
+
+var (
+	memberNtpEnabled = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixMember, "ntp_enabled"),
+		"NTP service enabled on the member (1=Enabled, 0=Disabled)",
+		nil, nil,
+	)
+	memberNtpServer = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixMember, "ntp_server_info"),
+		"NTP server used by the member, source is member if overridden on the member else grid",
+		memberNtpServerLabels, nil,
+	)
+	memberForwarder = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixMember, "dns_forwarder_info"),
+		"DNS forwarder used by the member, source is member if overridden on the member else grid",
+		memberForwarderLabels, nil,
+	)
+	memberForwardOnly = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixMember, "dns_forward_only"),
+		"DNS forward only enabled for the member (1=Enabled, 0=Disabled)",
+		nil, nil,
+	)
+)
+
+func probeMemberConfig(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	member, err := api.GetMemberNtp(target)
+	if err != nil {
+		return m, false
+	}
+
+	grid, err := api.GetGridNtp()
+	if err != nil {
+		return m, false
+	}
+
+	memberDns, err := api.GetMemberDns(target)
+	if err != nil {
+		return m, false
+	}
+
+	gridDns, err := api.GetGridDns()
+	if err != nil {
+		return m, false
+	}
+
+	m = metricsMemberNtp(member, grid, m)
+	m = metricsMemberForwarders(memberDns, gridDns, m)
+
+	return m, true
+}
+
+func metricsMemberNtp(member Member, grid Grid, m []prometheus.Metric) []prometheus.Metric {
+
+	if member.NtpSetting == nil {
+		return m
+	}
+
+	enabled := 0.0
+	if member.NtpSetting.EnableNTP {
+		enabled = 1.0
+	}
+	m = append(m, prometheus.MustNewConstMetric(memberNtpEnabled, prometheus.GaugeValue, enabled))
+
+	// Members that do not override the ntp servers use the grid ntp servers
+	source := "member"
+	servers := member.NtpSetting.NTPServers
+	if !member.NtpSetting.UseNtpServers {
+		source = "grid"
+		servers = nil
+		if grid.NtpSetting != nil {
+			servers = grid.NtpSetting.NTPServers
+		}
+	}
+
+	dup := make(map[string]string)
+	for _, server := range servers {
+		_, ok := dup[server.Address]
+		if ok {
+			continue
+		} else {
+			dup[server.Address] = server.Address
+		}
+		m = append(m, prometheus.MustNewConstMetric(memberNtpServer, prometheus.GaugeValue, 1.0,
+			server.Address, strconv.FormatBool(server.Preferred), source))
+	}
+
+	return m
+}
+
+func metricsMemberForwarders(memberDns MemberDns, gridDns GridDns, m []prometheus.Metric) []prometheus.Metric {
+
+	// Members that do not override the forwarders use the grid forwarders
+	source := "member"
+	forwarders := memberDns.Forwarders
+	forwardOnly := memberDns.ForwardOnly
+	if !memberDns.UseForwarders {
+		source = "grid"
+		forwarders = gridDns.Forwarders
+		forwardOnly = gridDns.ForwardOnly
+	}
+
+	dup := make(map[string]string)
+	for _, forwarder := range forwarders {
+		_, ok := dup[forwarder]
+		if ok {
+			continue
+		} else {
+			dup[forwarder] = forwarder
+		}
+		m = append(m, prometheus.MustNewConstMetric(memberForwarder, prometheus.GaugeValue, 1.0, forwarder, source))
+	}
+
+	only := 0.0
+	if forwardOnly {
+		only = 1.0
+	}
+	m = append(m, prometheus.MustNewConstMetric(memberForwardOnly, prometheus.GaugeValue, only))
+
+	return m
+}
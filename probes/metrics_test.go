@@ -0,0 +1,57 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var fqNameRegexp = regexp.MustCompile(`fqName: "([^"]+)"`)
+
+// metricValues return the value of each metric keyed by the name and the sorted labels, like
+// infoblox_member_restart_pending{service="DNS"}
+func metricValues(t *testing.T, metrics []prometheus.Metric) map[string]float64 {
+	t.Helper()
+
+	values := make(map[string]float64)
+	for _, metric := range metrics {
+		var pb dto.Metric
+		if err := metric.Write(&pb); err != nil {
+			t.Fatalf("write metric: %v", err)
+		}
+
+		var labels []string
+		for _, label := range pb.GetLabel() {
+			labels = append(labels, fmt.Sprintf("%s=%q", label.GetName(), label.GetValue()))
+		}
+		sort.Strings(labels)
+
+		name := fqNameRegexp.FindStringSubmatch(metric.Desc().String())[1]
+		key := name + "{" + strings.Join(labels, ",") + "}"
+		switch {
+		case pb.Gauge != nil:
+			values[key] = pb.GetGauge().GetValue()
+		case pb.Counter != nil:
+			values[key] = pb.GetCounter().GetValue()
+		}
+	}
+	return values
+}
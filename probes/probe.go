@@ -15,7 +15,10 @@ package probes
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -23,7 +26,8 @@ import (
 const prefix = "infoblox"
 
 type ProbeCollector struct {
-	metrics []prometheus.Metric
+	metrics       []prometheus.Metric
+	failureReason string
 }
 
 type TargetMetadata struct {
@@ -31,7 +35,20 @@ type TargetMetadata struct {
 	VersionMinor int
 }
 
-type probeFunc func(target string) ([]prometheus.Metric, bool)
+// NewTargetMetadata parse the major and minor version from a NIOS version like 8.6.2-49947
+func NewTargetMetadata(version string) TargetMetadata {
+	var metadata TargetMetadata
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) > 0 {
+		metadata.VersionMajor, _ = strconv.Atoi(parts[0])
+	}
+	if len(parts) > 1 {
+		metadata.VersionMinor, _ = strconv.Atoi(parts[1])
+	}
+	return metadata
+}
+
+type probeFunc func(api InfoBloxApi, target string) ([]prometheus.Metric, bool)
 
 type probeDetailedFunc struct {
 	name     string
@@ -48,19 +65,80 @@ func (p *ProbeCollector) Probe(ctx context.Context, target string, modules strin
 		aProbe = probeDetailedFunc{"member_services", probeMember}
 	case "dhcp_utilization":
 		aProbe = probeDetailedFunc{"dhcp_utilization", probeDhcpUtilization}
+	case "licenses":
+		aProbe = probeDetailedFunc{"licenses", probeLicenses}
+	case "capacity":
+		aProbe = probeDetailedFunc{"capacity", probeCapacity}
+	case "restart_status":
+		aProbe = probeDetailedFunc{"restart_status", probeRestartStatus}
+	case "certificates":
+		aProbe = probeDetailedFunc{"certificates", probeCertificates}
+	case "dtc":
+		aProbe = probeDetailedFunc{"dtc", probeDtc}
+	case "fixed_addresses":
+		aProbe = probeDetailedFunc{"fixed_addresses", probeFixedAddresses}
+	case "dns_records":
+		aProbe = probeDetailedFunc{"dns_records", probeDnsRecords}
+	case "stale_records":
+		aProbe = probeDetailedFunc{"stale_records", probeStaleRecords}
+	case "consistency":
+		aProbe = probeDetailedFunc{"consistency", probeConsistency}
+	case "scheduled_tasks":
+		aProbe = probeDetailedFunc{"scheduled_tasks", probeScheduledTasks}
+	case "discovery":
+		aProbe = probeDetailedFunc{"discovery", probeDiscovery}
+	case "threat_protection":
+		aProbe = probeDetailedFunc{"threat_protection", probeThreatProtection}
+	case "member_config":
+		aProbe = probeDetailedFunc{"member_config", probeMemberConfig}
+	case "grid":
+		aProbe = probeDetailedFunc{"grid", probeGrid}
 	default:
 		return false, fmt.Errorf("not a supported module")
 	}
 
-	m, ok := aProbe.function(target)
+	// The probe use the same api during the probe even if the configuration is reloaded
+	api, release := AcquireInfobloxApi()
+	defer release()
+	if api == nil {
+		return false, fmt.Errorf("no connection to the grid")
+	}
+
+	if api.CircuitOpen() {
+		p.failureReason = failureReason(ErrCircuitOpen)
+		return false, nil
+	}
+
+	probeApi := api.WithContext(ctx)
+	m, ok := aProbe.function(probeApi, target)
 	if !ok {
 		success = false
+		p.failureReason = failureReason(probeApi.Rejected())
 	}
 	p.metrics = append(p.metrics, m...)
 
 	return success, nil
 }
 
+// FailureReason return why the probe failed without calling the grid, like when the circuit
+// breaker is open or the WAPI requests were rejected by the limiter. Empty for other failures.
+func (p *ProbeCollector) FailureReason() string {
+	return p.failureReason
+}
+
+// failureReason return the reason label for an error from the limiter or the circuit breaker
+func failureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, ErrQueueFull):
+		return "queue_full"
+	case errors.Is(err, ErrDeadlineMissed):
+		return "deadline"
+	}
+	return ""
+}
+
 func (p *ProbeCollector) Collect(c chan<- prometheus.Metric) {
 	// Collect result of new probe functions
 	for _, m := range p.metrics {
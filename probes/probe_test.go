@@ -0,0 +1,38 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		err    error
+		reason string
+	}{
+		{nil, ""},
+		{ErrCircuitOpen, "circuit_open"},
+		{ErrQueueFull, "queue_full"},
+		{fmt.Errorf("get member: %w", ErrDeadlineMissed), "deadline"},
+		{errors.New("WAPI request error: 404('404 Not Found')"), ""},
+	}
+	for _, test := range tests {
+		if reason := failureReason(test.err); reason != test.reason {
+			t.Errorf("failureReason(%v) = %q, want %q", test.err, reason, test.reason)
+		}
+	}
+}
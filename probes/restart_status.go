@@ -0,0 +1,123 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var prefixRestart = fmt.Sprintf("%s_%s", prefixMember, "restart")
+var restartLabels = []string{"service"}
+var restartStatusLabels = []string{"service", "status"}
+
+var (
+	restartStatus = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixRestart, "status"),
+		"Service restart request state as reported by the grid master",
+		restartStatusLabels, nil,
+	)
+	restartPending = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixRestart, "pending"),
+		"Service restart pending (1=Pending, 0=Not pending)",
+		restartLabels, nil,
+	)
+	restartPendingSeconds = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixRestart, "pending_seconds"),
+		"Seconds since the grid master requested the restart, 0 if not pending",
+		restartLabels, nil,
+	)
+)
+
+// restartNeeded is the needed value of a grid:servicerestart:request when a restart is pending
+const restartNeeded = "REQUIRED"
+
+// restartServices maps the member service names to the services of the restart requests, a
+// request for ALL services apply to both
+var restartServices = map[string][]string{
+	"DNS":  {"DNS", "ALL"},
+	"DHCP": {"DHCP", "DHCPV4", "DHCPV6", "ALL"},
+}
+
+func probeRestartStatus(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	member, err := api.GetMember(target)
+	if err != nil {
+		return m, false
+	}
+
+	requests, err := api.GetServiceRestartRequests(target)
+	if err != nil {
+		return m, false
+	}
+
+	m = metricsRestartStatus(member, requests, time.Now(), m)
+
+	return m, true
+}
+
+func metricsRestartStatus(member Member, requests []ServiceRestartRequest, now time.Time,
+	m []prometheus.Metric) []prometheus.Metric {
+
+	// Only report the services that are active on the member
+	active := make(map[string]string)
+	for _, mem := range member.ServiceStatus {
+		if mem.Status != "INACTIVE" {
+			active[mem.Service] = mem.Status
+		}
+	}
+
+	for _, svc := range []string{"DNS", "DHCP"} {
+		_, ok := active[svc]
+		if !ok {
+			continue
+		}
+
+		// The restart is pending since the oldest request that need it
+		state := "NOT_REQUIRED"
+		var since int64
+		for _, request := range requests {
+			if !slices.Contains(restartServices[svc], request.Service) || request.Needed != restartNeeded {
+				continue
+			}
+			state = restartNeeded
+			if since == 0 || request.LastUpdatedTime < since {
+				since = request.LastUpdatedTime
+			}
+		}
+
+		m = append(m, prometheus.MustNewConstMetric(restartStatus, prometheus.GaugeValue, 1.0, svc, state))
+
+		if state != restartNeeded {
+			m = append(m, prometheus.MustNewConstMetric(restartPending, prometheus.GaugeValue, 0.0, svc))
+			m = append(m, prometheus.MustNewConstMetric(restartPendingSeconds, prometheus.GaugeValue, 0.0, svc))
+			continue
+		}
+
+		pendingSeconds := 0.0
+		if since > 0 && now.Unix() > since {
+			pendingSeconds = float64(now.Unix() - since)
+		}
+		m = append(m, prometheus.MustNewConstMetric(restartPending, prometheus.GaugeValue, 1.0, svc))
+		m = append(m, prometheus.MustNewConstMetric(restartPendingSeconds, prometheus.GaugeValue,
+			pendingSeconds, svc))
+	}
+
+	return m
+}
@@ -0,0 +1,104 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	ibclient "github.com/infobloxopen/infoblox-go-client/v2"
+)
+
+func TestMetricsRestartStatus(t *testing.T) {
+	now := time.Unix(1700003600, 0)
+	member := Member{
+		HostName: "ns1.foo.com",
+		ServiceStatus: []ibclient.Servicestatus{
+			{Service: "DNS", Status: "WORKING"},
+			{Service: "DHCP", Status: "WORKING"},
+			{Service: "NTP", Status: "INACTIVE"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		member   Member
+		requests []ServiceRestartRequest
+		want     map[string]float64
+	}{
+		{
+			name:   "no requests",
+			member: member,
+			want: map[string]float64{
+				`infoblox_member_restart_status{service="DNS",status="NOT_REQUIRED"}`:  1,
+				`infoblox_member_restart_pending{service="DNS"}`:                       0,
+				`infoblox_member_restart_pending_seconds{service="DNS"}`:               0,
+				`infoblox_member_restart_status{service="DHCP",status="NOT_REQUIRED"}`: 1,
+				`infoblox_member_restart_pending{service="DHCP"}`:                      0,
+				`infoblox_member_restart_pending_seconds{service="DHCP"}`:              0,
+			},
+		},
+		{
+			name:   "dns restart needed since the grid timestamp",
+			member: member,
+			requests: []ServiceRestartRequest{
+				{Service: "DNS", Needed: "REQUIRED", LastUpdatedTime: 1700000000},
+				{Service: "DHCP", Needed: "NOT_REQUIRED", LastUpdatedTime: 1690000000},
+			},
+			want: map[string]float64{
+				`infoblox_member_restart_status{service="DNS",status="REQUIRED"}`:      1,
+				`infoblox_member_restart_pending{service="DNS"}`:                       1,
+				`infoblox_member_restart_pending_seconds{service="DNS"}`:               3600,
+				`infoblox_member_restart_status{service="DHCP",status="NOT_REQUIRED"}`: 1,
+				`infoblox_member_restart_pending{service="DHCP"}`:                      0,
+				`infoblox_member_restart_pending_seconds{service="DHCP"}`:              0,
+			},
+		},
+		{
+			name:   "all services and dhcpv4 use the oldest request",
+			member: member,
+			requests: []ServiceRestartRequest{
+				{Service: "ALL", Needed: "REQUIRED", LastUpdatedTime: 1700003000},
+				{Service: "DHCPV4", Needed: "REQUIRED", LastUpdatedTime: 1700002600},
+			},
+			want: map[string]float64{
+				`infoblox_member_restart_status{service="DNS",status="REQUIRED"}`:  1,
+				`infoblox_member_restart_pending{service="DNS"}`:                   1,
+				`infoblox_member_restart_pending_seconds{service="DNS"}`:           600,
+				`infoblox_member_restart_status{service="DHCP",status="REQUIRED"}`: 1,
+				`infoblox_member_restart_pending{service="DHCP"}`:                  1,
+				`infoblox_member_restart_pending_seconds{service="DHCP"}`:          1000,
+			},
+		},
+		{
+			name: "inactive services are not reported",
+			member: Member{
+				HostName:      "ns2.foo.com",
+				ServiceStatus: []ibclient.Servicestatus{{Service: "DHCP", Status: "INACTIVE"}},
+			},
+			requests: []ServiceRestartRequest{{Service: "DHCP", Needed: "REQUIRED", LastUpdatedTime: 1700000000}},
+			want:     map[string]float64{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := metricValues(t, metricsRestartStatus(test.member, test.requests, now, nil))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
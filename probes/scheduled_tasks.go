@@ -0,0 +1,115 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var prefixScheduledTask = fmt.Sprintf("%s_%s", prefix, "scheduled_task")
+var scheduledTaskLabels = []string{"status"}
+var scheduledTaskApprovalLabels = []string{"submitter_group"}
+
+var (
+	scheduledTasks = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixScheduledTask, "count"),
+		"Number of scheduled tasks per execution status",
+		scheduledTaskLabels, nil,
+	)
+	scheduledTaskOldestPending = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixScheduledTask, "oldest_pending_seconds"),
+		"Age in seconds of the oldest pending scheduled task",
+		nil, nil,
+	)
+	scheduledTaskAwaitingApproval = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixScheduledTask, "awaiting_approval"),
+		"Number of scheduled tasks awaiting approval per submitter admin group",
+		scheduledTaskApprovalLabels, nil,
+	)
+)
+
+// pendingExecutionStatus are the execution status of tasks that have not been executed
+var pendingExecutionStatus = map[string]bool{
+	"PENDING":           true,
+	"WAITING_EXECUTION": true,
+}
+
+func probeScheduledTasks(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	tasks, err := api.GetScheduledTasks()
+	if err != nil {
+		return m, false
+	}
+
+	users, err := api.GetAdminUsers()
+	if err != nil {
+		return m, false
+	}
+
+	m = metricsScheduledTasks(tasks, users, m)
+
+	return m, true
+}
+
+func metricsScheduledTasks(tasks []ScheduledTask, users []AdminUser, m []prometheus.Metric) []prometheus.Metric {
+
+	// The task only include the submitter so the admin group is taken from the first group of the
+	// local admin user. Remote users, like ldap and radius, have no group.
+	submitterGroup := make(map[string]string)
+	for _, user := range users {
+		if len(user.AdminGroups) > 0 {
+			submitterGroup[user.Name] = user.AdminGroups[0]
+		}
+	}
+
+	now := time.Now().Unix()
+	status := make(map[string]int)
+	awaiting := make(map[string]int)
+	var oldestPending int64
+	for _, task := range tasks {
+		status[task.ExecutionStatus]++
+
+		if task.ApprovalStatus == "PENDING" {
+			awaiting[submitterGroup[task.Submitter]]++
+		}
+
+		if pendingExecutionStatus[task.ExecutionStatus] && task.SubmitTime > 0 {
+			if oldestPending == 0 || task.SubmitTime < oldestPending {
+				oldestPending = task.SubmitTime
+			}
+		}
+	}
+
+	for state, count := range status {
+		m = append(m, prometheus.MustNewConstMetric(scheduledTasks, prometheus.GaugeValue, float64(count), state))
+	}
+
+	for group, count := range awaiting {
+		m = append(m, prometheus.MustNewConstMetric(scheduledTaskAwaitingApproval, prometheus.GaugeValue,
+			float64(count), group))
+	}
+
+	age := 0.0
+	if oldestPending > 0 {
+		age = float64(now - oldestPending)
+	}
+	m = append(m, prometheus.MustNewConstMetric(scheduledTaskOldestPending, prometheus.GaugeValue, age))
+
+	return m
+}
@@ -0,0 +1,88 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var staleRecordLabels = []string{"type", "days"}
+var neverQueriedLabels = []string{"type"}
+
+// staleRecordTypes are the record types where the grid track last_queried
+var staleRecordTypes = []string{"record:a", "record:aaaa", "record:cname", "record:ptr", "record:mx",
+	"record:txt", "record:srv", "record:host"}
+
+var (
+	staleRecords = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixDns, "records_not_queried"),
+		"Number of DNS records in the zone not queried within the number of days",
+		staleRecordLabels, nil,
+	)
+	neverQueriedRecords = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixDns, "records_never_queried"),
+		"Number of DNS records in the zone that have no last queried time",
+		neverQueriedLabels, nil,
+	)
+)
+
+func probeStaleRecords(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	windows := api.Settings().GetIntSlice("modules.stale_records.days")
+	for _, recordType := range staleRecordTypes {
+		records, err := api.GetDnsRecords(recordType, target)
+		if err != nil {
+			return m, false
+		}
+
+		m = metricsStaleRecords(strings.TrimPrefix(recordType, "record:"), records, windows, m)
+	}
+
+	return m, true
+}
+
+func metricsStaleRecords(recordType string, records []DnsRecord, windows []int, m []prometheus.Metric) []prometheus.Metric {
+
+	now := time.Now()
+	neverQueried := 0
+	for _, rec := range records {
+		if rec.LastQueried == 0 {
+			neverQueried++
+		}
+	}
+	m = append(m, prometheus.MustNewConstMetric(neverQueriedRecords, prometheus.GaugeValue, float64(neverQueried),
+		recordType))
+
+	for _, days := range windows {
+		since := now.AddDate(0, 0, -days).Unix()
+		stale := 0
+		for _, rec := range records {
+			// Records never queried are counted in every window
+			if rec.LastQueried < since {
+				stale++
+			}
+		}
+		m = append(m, prometheus.MustNewConstMetric(staleRecords, prometheus.GaugeValue, float64(stale),
+			recordType, strconv.Itoa(days)))
+	}
+
+	return m
+}
@@ -0,0 +1,153 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var prefixRpz = fmt.Sprintf("%s_%s", prefix, "rpz")
+var prefixThreatProtection = fmt.Sprintf("%s_%s", prefix, "threat_protection")
+var rpzLabels = []string{"zone", "view", "rpz_type"}
+var rpzRuleTypeLabels = []string{"zone", "view", "rpz_type", "rule_type"}
+var threatProtectionLabels = []string{"version"}
+var memberThreatProtectionLabels = []string{"member", "node_ip", "ruleset"}
+
+var (
+	rpzRules = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixRpz, "rules"),
+		"Number of rules in the response policy zone",
+		rpzLabels, nil,
+	)
+	rpzRuleTypes = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixRpz, "rule_types"),
+		"Number of rules in the response policy zone per rule type",
+		rpzRuleTypeLabels, nil,
+	)
+	rpzLastUpdated = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixRpz, "last_updated_timestamp_seconds"),
+		"Last update of the response policy zone as unix timestamp in seconds",
+		rpzLabels, nil,
+	)
+	threatProtectionLastUpdate = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixThreatProtection, "last_rule_update_timestamp_seconds"),
+		"Last threat protection rule update as unix timestamp in seconds",
+		threatProtectionLabels, nil,
+	)
+	threatProtectionLastChecked = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixThreatProtection, "last_checked_for_update_timestamp_seconds"),
+		"Last check for threat protection rule updates as unix timestamp in seconds",
+		nil, nil,
+	)
+	memberThreatProtectionRuleset = prometheus.NewDesc(
+		fmt.Sprintf("%s_%s", prefixMember, "threat_protection_ruleset"),
+		"Threat protection ruleset used by the member (1=Enabled, 0=Disabled)",
+		memberThreatProtectionLabels, nil,
+	)
+)
+
+func probeThreatProtection(api InfoBloxApi, target string) ([]prometheus.Metric, bool) {
+
+	var m []prometheus.Metric
+
+	zones, err := api.GetRpzZones()
+	if err != nil {
+		return m, false
+	}
+
+	// The rules per rule type add a series for each type, so only the configured zones are broken down
+	typeZones := make(map[string]bool)
+	for _, zone := range api.Settings().GetStringSlice("modules.threat_protection.rule_type_zones") {
+		typeZones[zone] = true
+	}
+
+	for _, zone := range zones {
+		records, err := api.GetRpzRecords(zone.Fqdn, zone.View)
+		if err != nil {
+			return m, false
+		}
+		m = metricsRpzZone(zone, records, typeZones[zone.Fqdn], m)
+	}
+
+	grid, err := api.GetGridThreatProtection()
+	if err != nil {
+		return m, false
+	}
+
+	members, err := api.GetMemberThreatProtection()
+	if err != nil {
+		return m, false
+	}
+
+	m = metricsThreatProtection(grid, members, m)
+
+	return m, true
+}
+
+func metricsRpzZone(zone ZoneRp, records []AllRpzRecords, ruleTypes bool,
+	m []prometheus.Metric) []prometheus.Metric {
+
+	m = append(m, prometheus.MustNewConstMetric(rpzRules, prometheus.GaugeValue, float64(len(records)),
+		zone.Fqdn, zone.View, zone.RpzType))
+
+	if ruleTypes {
+		counts := make(map[string]int)
+		for _, rec := range records {
+			counts[strings.TrimPrefix(rec.Type, "record:rpz:")]++
+		}
+		for ruleType, count := range counts {
+			m = append(m, prometheus.MustNewConstMetric(rpzRuleTypes, prometheus.GaugeValue, float64(count),
+				zone.Fqdn, zone.View, zone.RpzType, ruleType))
+		}
+	}
+
+	if zone.RpzLastUpdatedTime > 0 {
+		m = append(m, prometheus.MustNewConstMetric(rpzLastUpdated, prometheus.GaugeValue,
+			float64(zone.RpzLastUpdatedTime), zone.Fqdn, zone.View, zone.RpzType))
+	}
+
+	return m
+}
+
+func metricsThreatProtection(grid GridThreatProtection, members []MemberThreatProtection,
+	m []prometheus.Metric) []prometheus.Metric {
+
+	if grid.LastRuleUpdateTimestamp > 0 {
+		m = append(m, prometheus.MustNewConstMetric(threatProtectionLastUpdate, prometheus.GaugeValue,
+			float64(grid.LastRuleUpdateTimestamp), grid.LastRuleUpdateVersion))
+	}
+	if grid.LastCheckedForUpdate > 0 {
+		m = append(m, prometheus.MustNewConstMetric(threatProtectionLastChecked, prometheus.GaugeValue,
+			float64(grid.LastCheckedForUpdate)))
+	}
+
+	for _, mem := range members {
+		// Members that do not override the ruleset use the grid ruleset
+		ruleset := mem.CurrentRuleset
+		if ruleset == "" {
+			ruleset = grid.CurrentRuleset
+		}
+		enabled := 0.0
+		if mem.EnableService {
+			enabled = 1.0
+		}
+		m = append(m, prometheus.MustNewConstMetric(memberThreatProtectionRuleset, prometheus.GaugeValue, enabled,
+			mem.HostName, mem.Ipv4Address, ruleset))
+	}
+
+	return m
+}
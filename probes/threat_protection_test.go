@@ -0,0 +1,72 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package probes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMetricsRpzZone(t *testing.T) {
+	local := ZoneRp{Fqdn: "local.rpz", View: "default", RpzType: "LOCAL", RpzLastUpdatedTime: 1729000000}
+	records := []AllRpzRecords{
+		{Type: "record:rpz:cname"}, {Type: "record:rpz:cname"}, {Type: "record:rpz:a:ipaddress"},
+	}
+
+	tests := []struct {
+		name      string
+		zone      ZoneRp
+		records   []AllRpzRecords
+		ruleTypes bool
+		want      map[string]float64
+	}{
+		{
+			name:    "rule count",
+			zone:    local,
+			records: records,
+			want: map[string]float64{
+				`infoblox_rpz_rules{rpz_type="LOCAL",view="default",zone="local.rpz"}`:                          3,
+				`infoblox_rpz_last_updated_timestamp_seconds{rpz_type="LOCAL",view="default",zone="local.rpz"}`: 1729000000,
+			},
+		},
+		{
+			name:      "rule types",
+			zone:      local,
+			records:   records,
+			ruleTypes: true,
+			want: map[string]float64{
+				`infoblox_rpz_rules{rpz_type="LOCAL",view="default",zone="local.rpz"}`:                              3,
+				`infoblox_rpz_rule_types{rpz_type="LOCAL",rule_type="cname",view="default",zone="local.rpz"}`:       2,
+				`infoblox_rpz_rule_types{rpz_type="LOCAL",rule_type="a:ipaddress",view="default",zone="local.rpz"}`: 1,
+				`infoblox_rpz_last_updated_timestamp_seconds{rpz_type="LOCAL",view="default",zone="local.rpz"}`:     1729000000,
+			},
+		},
+		{
+			name: "empty zone never updated",
+			zone: ZoneRp{Fqdn: "feed.rpz", View: "default", RpzType: "FEED"},
+			want: map[string]float64{
+				`infoblox_rpz_rules{rpz_type="FEED",view="default",zone="feed.rpz"}`: 0,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := metricValues(t, metricsRpzZone(test.zone, test.records, test.ruleTypes, nil))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
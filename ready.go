@@ -0,0 +1,187 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+
+	"go-infoblox-exporter/probes"
+	"go-infoblox-exporter/settings"
+)
+
+var gridReadyGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: MetricsPrefix + "grid_ready",
+	Help: "Grid master answered the readiness check (1=Ready, 0=Not ready)",
+},
+	[]string{"master"},
+)
+
+type GridStatus struct {
+	Master       string    `json:"master"`
+	ActiveMaster string    `json:"active_master,omitempty"`
+	Ready        bool      `json:"ready"`
+	LastCheck    time.Time `json:"last_check"`
+	Error        string    `json:"error,omitempty"`
+}
+
+type ReadyStatus struct {
+	Ready bool         `json:"ready"`
+	Grids []GridStatus `json:"grids"`
+}
+
+// readiness cache the result of the WAPI check so frequent readiness probes do not load the grid.
+// The mutex is not held during the check, so a slow grid master do not block concurrent callers.
+type readiness struct {
+	mu      sync.Mutex
+	checked time.Time
+	status  ReadyStatus
+	// check is the running check, nil if no check is running
+	check *readyCheck
+}
+
+type readyCheck struct {
+	done   chan struct{}
+	status ReadyStatus
+}
+
+var ready readiness
+
+// Status return the cached status, or check the grid if the cached status is older than
+// exporter.ready.cache_seconds. Only one check run at a time, meanwhile the cached status is returned
+// or, if there is none, the status of the running check.
+func (r *readiness) Status() ReadyStatus {
+	r.mu.Lock()
+	cacheTime := time.Duration(settings.Get().GetInt("exporter.ready.cache_seconds")) * time.Second
+	if !r.checked.IsZero() && (time.Since(r.checked) < cacheTime || r.check != nil) {
+		defer r.mu.Unlock()
+		return r.status
+	}
+	if r.check != nil {
+		check := r.check
+		r.mu.Unlock()
+		<-check.done
+		return check.status
+	}
+	check := &readyCheck{done: make(chan struct{})}
+	r.check = check
+	r.mu.Unlock()
+
+	r.run(check)
+	return check.status
+}
+
+// run check the grid and cache the result, unless the check was invalidated while it was running
+func (r *readiness) run(check *readyCheck) {
+	defer close(check.done)
+
+	timeout := time.Duration(settings.Get().GetInt("exporter.ready.timeout_seconds")) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	gridStatus := checkGrid(ctx)
+	check.status = ReadyStatus{Ready: gridStatus.Ready, Grids: []GridStatus{gridStatus}}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.check != check {
+		return
+	}
+	r.check = nil
+	r.status = check.status
+	r.checked = time.Now()
+
+	// The master may have changed by a reload
+	gridReadyGauge.Reset()
+	if gridStatus.Ready {
+		gridReadyGauge.WithLabelValues(gridStatus.Master).Set(1)
+	} else {
+		gridReadyGauge.WithLabelValues(gridStatus.Master).Set(0)
+	}
+}
+
+// Invalidate force a new check on the next Status, used when the grid connection is replaced. A
+// running check of the replaced connection is not cached.
+func (r *readiness) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checked = time.Time{}
+	r.check = nil
+}
+
+// checkGrid use the WAPI schema as a lightweight call to verify the connection and credentials. The
+// grid is not ready if the schema request has not answered when the ctx is done, the request is then
+// left to finish in the background.
+func checkGrid(ctx context.Context) GridStatus {
+	status := GridStatus{LastCheck: time.Now()}
+	api, release := probes.AcquireInfobloxApi()
+	if api == nil {
+		release()
+		status.Error = "no connection to the grid"
+		return status
+	}
+
+	status.Master = api.Config.Master
+	result := make(chan error, 1)
+	go func() {
+		defer release()
+		_, err := api.WithContext(ctx).GetSchema()
+		result <- err
+	}()
+
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = fmt.Errorf("readiness check timed out: %w", ctx.Err())
+	}
+	status.ActiveMaster = api.ActiveMaster()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Ready = true
+	return status
+}
+
+func readyHandler(w http.ResponseWriter, r *http.Request) {
+	status := ready.Status()
+
+	body, err := json.Marshal(status)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "endpoint": "ready"}).Error("marshal ready status failed")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if status.Ready {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	_, err = w.Write(body)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "endpoint": "ready"}).Error("write api response failed")
+	}
+}
@@ -0,0 +1,101 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"go-infoblox-exporter/probes"
+)
+
+// setTestApi set the api used by the readiness check to a grid master served by the handler
+func setTestApi(t *testing.T, handler http.Handler) {
+	t.Helper()
+
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := viper.New()
+	SetDefaultValues(v)
+	v.Set("infoblox.master", host)
+	v.Set("infoblox.master_port", port)
+	v.Set("infoblox.wapi_version", "2.10.5")
+	v.Set("infoblox.username", "admin")
+	v.Set("infoblox.password", "infoblox")
+	v.Set("exporter.ready.cache_seconds", 0)
+	v.Set("exporter.ready.timeout_seconds", 1)
+	api, err := probes.NewInfobloxApi(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	probes.SetInfobloxApi(api)
+	ready.Invalidate()
+}
+
+func TestReadinessTimeout(t *testing.T) {
+	var slow atomic.Bool
+	release := make(chan struct{})
+	setTestApi(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slow.Load() {
+			<-release
+		}
+		_, _ = w.Write([]byte(`{"supported_versions": ["2.10.5"]}`))
+	}))
+	defer close(release)
+
+	status := ready.Status()
+	if !status.Ready {
+		t.Fatalf("got not ready, error %s", status.Grids[0].Error)
+	}
+
+	slow.Store(true)
+	checked := make(chan ReadyStatus)
+	go func() {
+		checked <- ready.Status()
+	}()
+
+	// The cached status is returned while the check is running
+	time.Sleep(200 * time.Millisecond)
+	start := time.Now()
+	status = ready.Status()
+	if !status.Ready || time.Since(start) > 100*time.Millisecond {
+		t.Errorf("got ready %t after %s, want the cached status", status.Ready, time.Since(start))
+	}
+
+	select {
+	case status = <-checked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("readiness check not stopped by the timeout")
+	}
+	if status.Ready || !strings.Contains(status.Grids[0].Error, "timed out") {
+		t.Errorf("got ready %t, error %q, want timed out", status.Ready, status.Grids[0].Error)
+	}
+}
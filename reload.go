@@ -0,0 +1,149 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"go-infoblox-exporter/probes"
+)
+
+var (
+	reloadMutex sync.Mutex
+
+	reloadSuccessGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: MetricsPrefix + "config_last_reload_success",
+		Help: "Whether the last configuration reload attempt was successful (1=Success, 0=Failed)",
+	})
+	reloadTimestampGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: MetricsPrefix + "config_last_reload_success_timestamp_seconds",
+		Help: "Timestamp of the last successful configuration reload",
+	})
+)
+
+// validateConfiguration validate the content of the configuration file before it replace the
+// running configuration
+func validateConfiguration(content []byte) error {
+	v, err := parseConfiguration(content)
+	if err != nil {
+		return err
+	}
+
+	problems := configurationProblems(v)
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// reloadConfiguration validate and read the configuration file and swap the infoblox api used by
+// the probes. The previous api is logged out when the probes using it have timed out.
+func reloadConfiguration() error {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	err := reload()
+	if err != nil {
+		reloadSuccessGauge.Set(0)
+		log.WithFields(log.Fields{"error": err, "config": viper.ConfigFileUsed()}).Error("Configuration reload failed")
+		return err
+	}
+
+	reloadSuccessGauge.Set(1)
+	reloadTimestampGauge.SetToCurrentTime()
+	log.WithFields(log.Fields{"config": viper.ConfigFileUsed()}).Info("Configuration reloaded")
+	return nil
+}
+
+// loadConfiguration read the content of the configuration file into a new viper instance with the
+// defaults and env vars, like the configuration read at start
+func loadConfiguration(content []byte) (*viper.Viper, error) {
+	v := viper.New()
+	SetDefaultValues(v)
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// reload create the api from the new configuration before anything is replaced, so the running
+// configuration and api are kept if the new configuration is rejected. The file is read once so the
+// configuration that is validated is the one that is loaded.
+func reload() error {
+	content, err := os.ReadFile(viper.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+
+	err = validateConfiguration(content)
+	if err != nil {
+		return err
+	}
+
+	v, err := loadConfiguration(content)
+	if err != nil {
+		return err
+	}
+
+	api, err := probes.NewInfobloxApi(v)
+	if err != nil {
+		return err
+	}
+
+	// The api hold the configuration, so the probes never see the new configuration with the old api
+	previous := probes.SetInfobloxApi(api)
+	ready.Invalidate()
+	retireApi(previous)
+	return nil
+}
+
+// watchReloadSignal reload the configuration on SIGHUP
+func watchReloadSignal() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			_ = reloadConfiguration()
+		}
+	}()
+}
+
+func reloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Only POST requests allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := reloadConfiguration()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to reload config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
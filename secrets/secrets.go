@@ -0,0 +1,286 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"go-infoblox-exporter/settings"
+)
+
+// Provider return the current value of a secret. Implementations must be safe for concurrent use.
+type Provider interface {
+	Secret() (string, error)
+}
+
+var (
+	mu sync.Mutex
+	// providers is the cached providers of the running configuration
+	providers       = make(map[string]Provider)
+	providersConfig *viper.Viper
+)
+
+// Lookup return the provider for the configuration key, like exporter.basic_auth.password, in the
+// running configuration. The provider is created on first use and then reused so file and http
+// providers can cache the secret. The cache is cleared when the configuration is reloaded.
+func Lookup(key string) Provider {
+	v := settings.Get()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if v != providersConfig {
+		providers = make(map[string]Provider)
+		providersConfig = v
+	}
+	provider, ok := providers[key]
+	if !ok {
+		provider = NewProvider(v, key)
+		providers[key] = provider
+	}
+	return provider
+}
+
+// NewProvider create the provider for the configuration key. The provider is selected by, in order:
+//   - <key>_provider with a type of file, env or http
+//   - <key>_file, shorthand for the file provider
+//   - <key>, the secret in plain text in the configuration or as env var
+//
+// A provider that is not completely configured return the configuration error from Secret.
+func NewProvider(v *viper.Viper, key string) Provider {
+	providerKey := key + "_provider"
+	if v.IsSet(providerKey + ".type") {
+		missing := func(field string) Provider {
+			return errorProvider{err: fmt.Errorf("%s.%s must be set for the %s secret provider",
+				providerKey, field, v.GetString(providerKey+".type"))}
+		}
+		switch v.GetString(providerKey + ".type") {
+		case "file":
+			if v.GetString(providerKey+".path") == "" {
+				return missing("path")
+			}
+			return NewFileProvider(v.GetString(providerKey + ".path"))
+		case "env":
+			if v.GetString(providerKey+".name") == "" {
+				return missing("name")
+			}
+			return NewEnvProvider(v.GetString(providerKey + ".name"))
+		case "http":
+			if v.GetString(providerKey+".url") == "" {
+				return missing("url")
+			}
+			if v.GetString(providerKey+".field") == "" {
+				return missing("field")
+			}
+			return NewHTTPProvider(v.GetString(providerKey+".url"), v.GetString(providerKey+".field"),
+				time.Duration(v.GetInt(providerKey+".refresh"))*time.Second)
+		default:
+			return errorProvider{err: fmt.Errorf("unknown secret provider type %s for %s",
+				v.GetString(providerKey+".type"), key)}
+		}
+	}
+
+	if v.GetString(key+"_file") != "" {
+		return NewFileProvider(v.GetString(key + "_file"))
+	}
+
+	return StaticProvider{Value: v.GetString(key)}
+}
+
+// Validate return the configuration error of the provider for the key, nil if the provider is
+// configured. The secret itself is not read.
+func Validate(v *viper.Viper, key string) error {
+	if provider, ok := NewProvider(v, key).(errorProvider); ok {
+		return provider.err
+	}
+	return nil
+}
+
+// StaticProvider return a fixed secret
+type StaticProvider struct {
+	Value string
+}
+
+func (p StaticProvider) Secret() (string, error) {
+	return p.Value, nil
+}
+
+type errorProvider struct {
+	err error
+}
+
+func (p errorProvider) Secret() (string, error) {
+	return "", p.err
+}
+
+// FileProvider read the secret from a file and read it again when the file is changed, like when a
+// Kubernetes secret is rotated. Trailing newlines are removed.
+type FileProvider struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time
+	value   string
+}
+
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+func (p *FileProvider) Secret() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return "", err
+	}
+	if !info.ModTime().Equal(p.modTime) {
+		content, err := os.ReadFile(p.path)
+		if err != nil {
+			return "", err
+		}
+		p.value = strings.TrimRight(string(content), "\r\n")
+		p.modTime = info.ModTime()
+	}
+	return p.value, nil
+}
+
+// EnvProvider read the secret from an environment variable
+type EnvProvider struct {
+	name string
+}
+
+func NewEnvProvider(name string) EnvProvider {
+	return EnvProvider{name: name}
+}
+
+func (p EnvProvider) Secret() (string, error) {
+	value, ok := os.LookupEnv(p.name)
+	if !ok {
+		return "", fmt.Errorf("env var %s not set", p.name)
+	}
+	return value, nil
+}
+
+// HTTPProvider fetch the secret from a http endpoint that return a json object. The field is the
+// path to the secret in the object, like data.password. The secret is cached for the refresh period,
+// default 60 seconds. If a refresh fail the last secret is used and the fetch is retried with a
+// backoff, so an unavailable endpoint do not fail or slow down every request.
+type HTTPProvider struct {
+	url     string
+	field   string
+	refresh time.Duration
+	client  http.Client
+	mu      sync.Mutex
+	fetched time.Time
+	value   string
+
+	// retryAt is when a failed fetch is retried, the backoff is doubled for each failure
+	retryAt time.Time
+	backoff time.Duration
+	err     error
+}
+
+func NewHTTPProvider(url string, field string, refresh time.Duration) *HTTPProvider {
+	if refresh <= 0 {
+		refresh = 60 * time.Second
+	}
+	return &HTTPProvider{
+		url:     url,
+		field:   field,
+		refresh: refresh,
+		client:  http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *HTTPProvider) Secret() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if !p.fetched.IsZero() && now.Sub(p.fetched) < p.refresh {
+		return p.value, nil
+	}
+	if now.Before(p.retryAt) {
+		return p.lastValue()
+	}
+
+	value, err := p.fetch()
+	if err != nil {
+		p.backoff = min(max(2*p.backoff, time.Second), p.refresh)
+		p.retryAt = now.Add(p.backoff)
+		p.err = err
+		log.WithFields(log.Fields{"url": p.url, "error": err, "retry": p.backoff.String(),
+			"cached": !p.fetched.IsZero()}).Warn("Failed to refresh secret")
+		return p.lastValue()
+	}
+
+	p.value = value
+	p.fetched = now
+	p.backoff = 0
+	p.retryAt = time.Time{}
+	p.err = nil
+	return p.value, nil
+}
+
+// lastValue return the last fetched secret, or the fetch error if there has never been a secret
+func (p *HTTPProvider) lastValue() (string, error) {
+	if p.fetched.IsZero() {
+		return "", p.err
+	}
+	return p.value, nil
+}
+
+func (p *HTTPProvider) fetch() (string, error) {
+	resp, err := p.client.Get(p.url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("secret provider %s returned status %d", p.url, resp.StatusCode)
+	}
+
+	var body interface{}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range strings.Split(p.field, ".") {
+		object, ok := body.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("secret field %s not found", p.field)
+		}
+		body, ok = object[name]
+		if !ok {
+			return "", fmt.Errorf("secret field %s not found", p.field)
+		}
+	}
+
+	value, ok := body.(string)
+	if !ok {
+		return "", fmt.Errorf("secret field %s is not a string", p.field)
+	}
+	return value, nil
+}
@@ -0,0 +1,251 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package secrets
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestNewProvider(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "password")
+	err := os.WriteFile(file, []byte("from file\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_SECRET", "from env")
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		value  string
+		err    string
+	}{
+		{"static", map[string]interface{}{"password": "static"}, "static", ""},
+		{"file shorthand before static",
+			map[string]interface{}{"password": "static", "password_file": file}, "from file", ""},
+		{"provider before file shorthand",
+			map[string]interface{}{"password_file": file,
+				"password_provider": map[string]interface{}{"type": "env", "name": "TEST_SECRET"}}, "from env", ""},
+		{"file provider",
+			map[string]interface{}{"password_provider": map[string]interface{}{"type": "file", "path": file}},
+			"from file", ""},
+		{"file provider without path",
+			map[string]interface{}{"password_provider": map[string]interface{}{"type": "file"}},
+			"", "password_provider.path must be set"},
+		{"env provider without name",
+			map[string]interface{}{"password_provider": map[string]interface{}{"type": "env"}},
+			"", "password_provider.name must be set"},
+		{"http provider without url",
+			map[string]interface{}{"password_provider": map[string]interface{}{"type": "http", "field": "password"}},
+			"", "password_provider.url must be set"},
+		{"http provider without field",
+			map[string]interface{}{"password_provider": map[string]interface{}{"type": "http", "url": "http://localhost"}},
+			"", "password_provider.field must be set"},
+		{"unknown provider",
+			map[string]interface{}{"password_provider": map[string]interface{}{"type": "vault"}},
+			"", "unknown secret provider type vault"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := viper.New()
+			err := v.MergeConfigMap(test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			value, err := NewProvider(v, "password").Secret()
+			validateErr := Validate(v, "password")
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want %q", err, test.err)
+				}
+				if validateErr == nil || validateErr.Error() != err.Error() {
+					t.Fatalf("got validate error %v, want %v", validateErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if validateErr != nil {
+				t.Fatalf("got validate error %v", validateErr)
+			}
+			if value != test.value {
+				t.Errorf("got %q, want %q", value, test.value)
+			}
+		})
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "password")
+	write := func(content string, modTime time.Time) {
+		t.Helper()
+		err := os.WriteFile(file, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Chtimes(file, modTime, modTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	modTime := time.Now().Add(-time.Hour)
+	write("first\r\n\n", modTime)
+	provider := NewFileProvider(file)
+	value, err := provider.Secret()
+	if err != nil || value != "first" {
+		t.Fatalf("got %q, %v, want first", value, err)
+	}
+
+	// The file is not read again if the modification time is the same
+	write("other", modTime)
+	value, err = provider.Secret()
+	if err != nil || value != "first" {
+		t.Fatalf("got %q, %v, want first", value, err)
+	}
+
+	write("second\n", modTime.Add(time.Minute))
+	value, err = provider.Secret()
+	if err != nil || value != "second" {
+		t.Fatalf("got %q, %v, want second", value, err)
+	}
+}
+
+func TestHTTPProvider(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		field  string
+		value  string
+		err    string
+	}{
+		{"field path", http.StatusOK, `{"data": {"password": "secret"}}`, "data.password", "secret", ""},
+		{"top level field", http.StatusOK, `{"password": "secret"}`, "password", "secret", ""},
+		{"status", http.StatusForbidden, `{"password": "secret"}`, "password", "", "returned status 403"},
+		{"missing field", http.StatusOK, `{"data": {}}`, "data.password", "", "not found"},
+		{"field in non object", http.StatusOK, `{"data": "secret"}`, "data.password", "", "not found"},
+		{"non string field", http.StatusOK, `{"password": 42}`, "password", "", "is not a string"},
+		{"invalid json", http.StatusOK, `password`, "password", "", "invalid character"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, test.body)
+			}))
+			defer server.Close()
+
+			value, err := NewHTTPProvider(server.URL, test.field, 0).Secret()
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value != test.value {
+				t.Errorf("got %q, want %q", value, test.value)
+			}
+		})
+	}
+}
+
+func TestHTTPProviderCache(t *testing.T) {
+	var requests atomic.Int32
+	var fail atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"password": "secret-%d"}`, n)
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(server.URL, "password", time.Minute)
+	secret := func(want string, wantRequests int32) {
+		t.Helper()
+		value, err := provider.Secret()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != want {
+			t.Errorf("got %q, want %q", value, want)
+		}
+		if requests.Load() != wantRequests {
+			t.Errorf("got %d requests, want %d", requests.Load(), wantRequests)
+		}
+	}
+	expire := func() {
+		provider.mu.Lock()
+		provider.fetched = provider.fetched.Add(-time.Minute)
+		provider.retryAt = time.Time{}
+		provider.mu.Unlock()
+	}
+
+	secret("secret-1", 1)
+	// Cached for the refresh period
+	secret("secret-1", 1)
+
+	expire()
+	secret("secret-2", 2)
+
+	// A failed refresh return the last secret and is not retried until the backoff has passed
+	fail.Store(true)
+	expire()
+	secret("secret-2", 3)
+	secret("secret-2", 3)
+
+	fail.Store(false)
+	expire()
+	secret("secret-4", 4)
+}
+
+func TestHTTPProviderNeverFetched(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(server.URL, "password", time.Minute)
+	for i := 0; i < 2; i++ {
+		_, err := provider.Secret()
+		if err == nil || !strings.Contains(err.Error(), "returned status 500") {
+			t.Fatalf("got error %v, want status 500", err)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("got %d requests during the backoff, want 1", requests.Load())
+	}
+}
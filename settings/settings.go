@@ -0,0 +1,41 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package settings
+
+import (
+	"sync/atomic"
+
+	"github.com/spf13/viper"
+)
+
+// source return the running configuration, it is set by the probes package where the configuration
+// is published together with the infoblox api
+var source atomic.Pointer[func() *viper.Viper]
+
+// SetSource set the function that return the running configuration, nil until a configuration is
+// published
+func SetSource(f func() *viper.Viper) {
+	source.Store(&f)
+}
+
+// Get return the running configuration. Until a configuration is published the global viper
+// instance is used.
+func Get() *viper.Viper {
+	if f := source.Load(); f != nil {
+		if v := (*f)(); v != nil {
+			return v
+		}
+	}
+	return viper.GetViper()
+}
@@ -0,0 +1,102 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"go-infoblox-exporter/probes"
+	"go-infoblox-exporter/settings"
+)
+
+var (
+	retiredMutex sync.Mutex
+	// retired is the apis replaced by a reload, logged out when the probes using them are done
+	retired = make(map[*probes.InfoBloxApi]bool)
+)
+
+// retireApi log out the api when the probes and readiness checks using it are done
+func retireApi(api *probes.InfoBloxApi) {
+	if api == nil {
+		return
+	}
+
+	retiredMutex.Lock()
+	retired[api] = true
+	retiredMutex.Unlock()
+
+	go func() {
+		<-api.Retire()
+
+		// The api is already logged out if the exporter is shut down
+		retiredMutex.Lock()
+		ok := retired[api]
+		delete(retired, api)
+		retiredMutex.Unlock()
+		if ok {
+			api.Logout()
+			log.WithFields(log.Fields{"master": api.Config.Master}).Info("Logged out retired connection from grid")
+		}
+
+		// The series of a master that is no longer configured would otherwise keep their last value
+		current := probes.GetInfobloxApi()
+		if current != nil && current.Config.Master != api.Config.Master {
+			api.DeleteMetrics()
+		}
+	}()
+}
+
+// logoutAll log out the current and all retired apis so no sessions are left on the grid master
+func logoutAll() {
+	retiredMutex.Lock()
+	apis := []*probes.InfoBloxApi{probes.GetInfobloxApi()}
+	for api := range retired {
+		apis = append(apis, api)
+		delete(retired, api)
+	}
+	retiredMutex.Unlock()
+
+	for _, api := range apis {
+		if api == nil {
+			continue
+		}
+		api.Logout()
+		log.WithFields(log.Fields{"master": api.Config.Master}).Info("Logged out from grid")
+	}
+}
+
+// shutdown stop accepting new requests and wait for the in-flight probes to finish, at most the
+// exporter.shutdown_grace_period, before logging out from the grid
+func shutdown(s *http.Server, sig os.Signal) {
+	gracePeriod := time.Duration(settings.Get().GetInt("exporter.shutdown_grace_period")) * time.Second
+	log.WithFields(log.Fields{"signal": sig.String(), "grace_period": gracePeriod.String()}).Info("Shutting down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+
+	err := s.Shutdown(ctx)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("Shutdown grace period expired, in-flight requests aborted")
+		s.Close()
+	}
+
+	logoutAll()
+	log.Info(ExporterName + " stopped")
+}
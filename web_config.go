@@ -0,0 +1,216 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+var tlsVersions = map[string]uint16{
+	"TLS10": tls.VersionTLS10,
+	"TLS11": tls.VersionTLS11,
+	"TLS12": tls.VersionTLS12,
+	"TLS13": tls.VersionTLS13,
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"NoClientCert":               tls.NoClientCert,
+	"RequestClientCert":          tls.RequestClientCert,
+	"RequireAnyClientCert":       tls.RequireAnyClientCert,
+	"VerifyClientCertIfGiven":    tls.VerifyClientCertIfGiven,
+	"RequireAndVerifyClientCert": tls.RequireAndVerifyClientCert,
+}
+
+// TLSConfiguration is the exporter web server tls configuration, the naming follow the
+// Prometheus exporter-toolkit web configuration
+type TLSConfiguration struct {
+	CertFile       string
+	KeyFile        string
+	ClientCAFile   string
+	ClientAuthType string
+	MinVersion     string
+	CipherSuites   []string
+}
+
+func NewTLSConfiguration() TLSConfiguration {
+	return TLSConfiguration{
+		CertFile:       viper.GetString("exporter.tls.cert_file"),
+		KeyFile:        viper.GetString("exporter.tls.key_file"),
+		ClientCAFile:   viper.GetString("exporter.tls.client_ca_file"),
+		ClientAuthType: viper.GetString("exporter.tls.client_auth_type"),
+		MinVersion:     viper.GetString("exporter.tls.min_version"),
+		CipherSuites:   viper.GetStringSlice("exporter.tls.cipher_suites"),
+	}
+}
+
+// Enabled return true if the exporter should serve https
+func (c TLSConfiguration) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// certReloader keep the server certificate and client CAs and reload them when the files change
+// on disk, so rotated certificates are used without a restart of the exporter
+type certReloader struct {
+	mu      sync.Mutex
+	config  TLSConfiguration
+	modTime time.Time
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+}
+
+// NewServerTLSConfig create the tls.Config for the exporter web server. The certificate files are
+// read when the configuration is created and then checked for changes on every new connection.
+func NewServerTLSConfig(config TLSConfiguration) (*tls.Config, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, fmt.Errorf("both cert_file and key_file must be set")
+	}
+
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.NoClientCert,
+	}
+
+	if config.MinVersion != "" {
+		version, ok := tlsVersions[config.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown min_version %s", config.MinVersion)
+		}
+		base.MinVersion = version
+	}
+
+	if len(config.CipherSuites) > 0 {
+		suites := make(map[string]uint16)
+		for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+			suites[suite.Name] = suite.ID
+		}
+		for _, name := range config.CipherSuites {
+			id, ok := suites[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown cipher suite %s", name)
+			}
+			base.CipherSuites = append(base.CipherSuites, id)
+		}
+		// The http server refuse to start HTTP/2 without one of the suites required by HTTP/2
+		if !slices.Contains(base.CipherSuites, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256) &&
+			!slices.Contains(base.CipherSuites, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256) {
+			return nil, fmt.Errorf("cipher_suites must include TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 or " +
+				"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256 required by HTTP/2")
+		}
+	}
+
+	if config.ClientCAFile != "" {
+		// Default to mutual tls if a client CA is configured
+		base.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if config.ClientAuthType != "" {
+		authType, ok := clientAuthTypes[config.ClientAuthType]
+		if !ok {
+			return nil, fmt.Errorf("unknown client_auth_type %s", config.ClientAuthType)
+		}
+		base.ClientAuth = authType
+	}
+	if config.ClientCAFile == "" && (base.ClientAuth == tls.VerifyClientCertIfGiven ||
+		base.ClientAuth == tls.RequireAndVerifyClientCert) {
+		return nil, fmt.Errorf("client_auth_type %s requires client_ca_file", config.ClientAuthType)
+	}
+
+	reloader := &certReloader{config: config}
+	err := reloader.reload()
+	if err != nil {
+		return nil, err
+	}
+
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cert, pool := reloader.get()
+		c := base.Clone()
+		c.GetConfigForClient = nil
+		// The config returned here replace the config of the server, where h2 is added
+		c.NextProtos = []string{"h2", "http/1.1"}
+		c.Certificates = []tls.Certificate{*cert}
+		c.ClientCAs = pool
+		return c, nil
+	}
+
+	return base, nil
+}
+
+// get return the current certificate and client CAs, reloaded if any of the files has changed
+func (r *certReloader) get() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lastModified().After(r.modTime) {
+		err := r.reload()
+		if err != nil {
+			// Keep the current certificate, the files may be in the middle of a rotation
+			log.WithFields(log.Fields{"error": err}).Error("Reload of tls certificate failed")
+		} else {
+			log.Info("Reloaded tls certificate")
+		}
+	}
+	return r.cert, r.pool
+}
+
+func (r *certReloader) reload() error {
+	modTime := r.lastModified()
+
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	var pool *x509.CertPool
+	if r.config.ClientCAFile != "" {
+		ca, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("no certificates found in %s", r.config.ClientCAFile)
+		}
+	}
+
+	r.cert = &cert
+	r.pool = pool
+	r.modTime = modTime
+	return nil
+}
+
+// lastModified return the latest modification time of the certificate files
+func (r *certReloader) lastModified() time.Time {
+	var latest time.Time
+	for _, file := range []string{r.config.CertFile, r.config.KeyFile, r.config.ClientCAFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
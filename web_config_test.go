@@ -0,0 +1,217 @@
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Copyright 2023-2025 Anders Håål
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertificate write a self signed certificate and key with the common name to the files
+func writeCertificate(t *testing.T, certFile string, keyFile string, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// touch set the modification time of the files so the change is seen on file systems with a coarse
+// modification time
+func touch(t *testing.T, modTime time.Time, files ...string) {
+	t.Helper()
+
+	for _, file := range files {
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func serverCommonName(t *testing.T, config *tls.Config) string {
+	t.Helper()
+
+	c, err := config.GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(c.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	writeCertificate(t, certFile, keyFile, "first")
+	touch(t, time.Now().Add(-time.Minute), certFile, keyFile)
+
+	config, err := NewServerTLSConfig(TLSConfiguration{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("NewServerTLSConfig: %v", err)
+	}
+	if name := serverCommonName(t, config); name != "first" {
+		t.Errorf("certificate %s, want first", name)
+	}
+
+	c, err := config.GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.NextProtos) == 0 || c.NextProtos[0] != "h2" {
+		t.Errorf("next protos %v, want h2 first", c.NextProtos)
+	}
+
+	// A rotated certificate is used on the next connection
+	writeCertificate(t, certFile, keyFile, "second")
+	touch(t, time.Now(), certFile, keyFile)
+	if name := serverCommonName(t, config); name != "second" {
+		t.Errorf("certificate %s after rotation, want second", name)
+	}
+
+	// A broken certificate keep the current certificate
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, time.Now().Add(time.Minute), certFile)
+	if name := serverCommonName(t, config); name != "second" {
+		t.Errorf("certificate %s after a failed reload, want second", name)
+	}
+}
+
+func TestNewServerTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	writeCertificate(t, certFile, keyFile, "server")
+
+	tests := []struct {
+		name    string
+		config  TLSConfiguration
+		wantErr bool
+		check   func(*tls.Config) bool
+	}{
+		{
+			name:   "defaults",
+			config: TLSConfiguration{CertFile: certFile, KeyFile: keyFile},
+			check: func(c *tls.Config) bool {
+				return c.MinVersion == tls.VersionTLS12 && c.ClientAuth == tls.NoClientCert
+			},
+		},
+		{
+			name:   "client ca default to mutual tls",
+			config: TLSConfiguration{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile},
+			check: func(c *tls.Config) bool {
+				return c.ClientAuth == tls.RequireAndVerifyClientCert
+			},
+		},
+		{
+			name: "min version and cipher suites",
+			config: TLSConfiguration{CertFile: certFile, KeyFile: keyFile, MinVersion: "TLS13",
+				CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}},
+			check: func(c *tls.Config) bool {
+				return c.MinVersion == tls.VersionTLS13 && len(c.CipherSuites) == 1 &&
+					c.CipherSuites[0] == tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+			},
+		},
+		{
+			name:    "missing key file",
+			config:  TLSConfiguration{CertFile: certFile},
+			wantErr: true,
+		},
+		{
+			name:    "unknown min version",
+			config:  TLSConfiguration{CertFile: certFile, KeyFile: keyFile, MinVersion: "TLS14"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown cipher suite",
+			config:  TLSConfiguration{CertFile: certFile, KeyFile: keyFile, CipherSuites: []string{"NO_SUCH_SUITE"}},
+			wantErr: true,
+		},
+		{
+			name: "cipher suites without the HTTP/2 suite",
+			config: TLSConfiguration{CertFile: certFile, KeyFile: keyFile,
+				CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}},
+			wantErr: true,
+		},
+		{
+			name: "verify client certificates without a client ca",
+			config: TLSConfiguration{CertFile: certFile, KeyFile: keyFile,
+				ClientAuthType: "VerifyClientCertIfGiven"},
+			wantErr: true,
+		},
+		{
+			name:    "client ca without certificates",
+			config:  TLSConfiguration{CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, err := NewServerTLSConfig(test.config)
+			if test.wantErr {
+				if err == nil {
+					t.Error("no error for an invalid configuration")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewServerTLSConfig: %v", err)
+			}
+			if !test.check(config) {
+				t.Errorf("unexpected tls config %+v", config)
+			}
+		})
+	}
+}